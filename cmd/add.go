@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"os"
+	"strconv"
+	"strings"
 
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/fuzzy"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
 	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/names"
 	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/validation"
 	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
 	"github.com/spf13/cobra"
 )
@@ -13,10 +19,20 @@ import (
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a new book",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Add a new book.
+
+Title, author and year are required. When one is missing and the command
+runs in a terminal, it asks for the missing fields, and for the year and
+status unless given. Typing the start of an author's name, or the name
+with a typo, offers the matching authors of the library.
+
+A book that is likely already in the library, having the same ISBN or a
+similar title and author, is added with a warning, or refused with
+--strict.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		db, err := db.InitDB()
 		if err != nil {
-			log.Fatalf("Failed to connect to database: %v", err)
+			return i18n.Errorf("failed to connect to database: %w", err)
 		}
 		defer db.Close()
 
@@ -31,20 +47,158 @@ var addCmd = &cobra.Command{
 			Title:         title,
 			Author:        author,
 			PublishedYear: year,
-			Status:        status,
+			Status:        strings.ToLower(status),
+		}
+		if err := applyBookFieldFlags(cmd, &book); err != nil {
+			return i18n.Errorf("failed to add book: %w", err)
+		}
+
+		if missing := missingBookFields(book); len(missing) > 0 {
+			if !interactive() {
+				return usagef("missing %s", strings.Join(missing, ", "))
+			}
+			authors, err := repository.NewAuthorRepository(db).GetAllAuthors()
+			if err != nil {
+				return i18n.Errorf("failed to get authors: %w", err)
+			}
+			if err := askBookFields(cmd, &book, authors); err != nil {
+				return i18n.Errorf("failed to add book: %w", err)
+			}
 		}
 
-		if err := repo.AddBook(book); err != nil {
-			log.Fatalf("Failed to add book: %v", err)
+		dups, err := repo.FindDuplicates(book)
+		if err != nil {
+			return i18n.Errorf("failed to look for duplicates: %w", err)
 		}
-		fmt.Println("Book added successfully!")
+		if len(dups) > 0 {
+			if strict, _ := cmd.Flags().GetBool("strict"); strict {
+				return conflictf("likely a duplicate of %s", describeDuplicates(dups))
+			}
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("Warning: likely a duplicate of %s; see book dedupe", describeDuplicates(dups)))
+		}
+
+		id, err := repo.AddBook(book)
+		if err != nil {
+			return i18n.Errorf("failed to add book: %w", err)
+		}
+		return printMessage(cmd, id, "Book added successfully!")
 	},
 }
 
+// missingBookFields lists the required fields book lacks, with their flags.
+func missingBookFields(book models.Book) []string {
+	var missing []string
+	if validation.Title(book.Title) != nil {
+		missing = append(missing, i18n.T("title (--title)"))
+	}
+	if validation.Author(book.Author) != nil {
+		missing = append(missing, i18n.T("author (--author)"))
+	}
+	if book.PublishedYear == 0 {
+		missing = append(missing, i18n.T("year (--year)"))
+	}
+	return missing
+}
+
+// askBookFields asks for the required fields book lacks, and for year and
+// status when their flags were not given, offering their defaults. Answers
+// are checked like the repository will check them.
+func askBookFields(cmd *cobra.Command, book *models.Book, authors []models.Author) error {
+	var err error
+	if validation.Title(book.Title) != nil {
+		book.Title, err = ask(i18n.T("Title"), "", func(answer string) (string, error) {
+			return answer, validation.Title(answer)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if validation.Author(book.Author) != nil {
+		book.Author, err = ask(i18n.T("Author"), "", func(answer string) (string, error) {
+			if err := validation.Author(answer); err != nil {
+				return "", err
+			}
+			return completeAuthor(answer, authors)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if !cmd.Flags().Changed("year") {
+		def := ""
+		if book.PublishedYear != 0 {
+			def = strconv.Itoa(book.PublishedYear)
+		}
+		year, err := ask(i18n.T("Year"), def, func(answer string) (string, error) {
+			year, err := strconv.Atoi(answer)
+			if answer != "" && err != nil {
+				return "", i18n.Errorf("%q is not a year", answer)
+			}
+			return answer, validation.Year(year)
+		})
+		if err != nil {
+			return err
+		}
+		book.PublishedYear, _ = strconv.Atoi(year)
+	}
+	if !cmd.Flags().Changed("status") {
+		def := book.Status
+		if def == "" {
+			def = "unread"
+		}
+		book.Status, err = ask(i18n.Sprintf("Status (%s)", strings.Join(models.Statuses, "/")), def, func(answer string) (string, error) {
+			answer = strings.ToLower(answer)
+			return answer, validation.Status(answer)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeAuthor returns the author name to use for a typed one: a known
+// name or alias as typed, otherwise one of the authors whose name starts
+// with or resembles it, or the typed name for a new author.
+func completeAuthor(typed string, authors []models.Author) (string, error) {
+	key := names.Key(typed)
+	var candidates []string
+	for _, a := range authors {
+		matched := false
+		for _, name := range append([]string{a.Name}, a.Aliases...) {
+			if names.Key(name) == key {
+				return typed, nil
+			}
+			score, _ := fuzzy.Match(typed, name)
+			matched = matched || score >= fuzzy.MinScore
+		}
+		if matched && len(candidates) < 9 {
+			candidates = append(candidates, a.Name)
+		}
+	}
+	if len(candidates) == 0 {
+		return typed, nil
+	}
+
+	options := append(candidates, i18n.Sprintf("%s (new author)", typed))
+	i, err := choose(i18n.T("Did you mean:"), options)
+	if err != nil {
+		return "", err
+	}
+	if i == len(candidates) {
+		return typed, nil
+	}
+	return candidates[i], nil
+}
+
 func init() {
 	rootCmd.AddCommand(addCmd)
 	addCmd.Flags().StringP("title", "t", "", "Book title")
 	addCmd.Flags().StringP("author", "a", "", "Book author")
 	addCmd.Flags().StringP("status", "s", "", "Book status (read/unread)")
 	addCmd.Flags().IntP("year", "y", 0, "Published year")
+	addCmd.Flags().Bool("strict", false, "Refuse to add a likely duplicate of a book in the library")
+	addCmd.RegisterFlagCompletionFunc("author", completeFieldValues("author"))
+	addCmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(models.Statuses, cobra.ShellCompDirectiveNoFileComp))
+	addBookFieldFlags(addCmd)
 }
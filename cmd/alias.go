@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/config"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+const aliasHelp = `Aliases are commands of your own, defined in the [alias] table of the
+configuration file (see book config) as the command line they run:
+
+    [alias]
+    unread = "list --where 'status = unread' --sort title"
+    by = "list --where \"author ~ '$1'\" --sort year"
+
+$1 to $9 stand for the arguments of the alias and $@ for all of them; an
+alias without them gets its arguments appended, so "book unread -n 5"
+runs "book list --where 'status = unread' --sort title -n 5". $$ is a
+literal $.
+
+Quote arguments that go into a filter expression, as '$1' above: within
+quotes, the quotes and backslashes of an argument are escaped, so an
+argument such as O'Brien is taken as text and cannot change the
+expression.`
+
+// maxAliasDepth limits how many aliases may expand into one another, so
+// that an alias running itself fails instead of looping.
+const maxAliasDepth = 10
+
+var aliasDepth int
+
+// registerAliases adds the aliases of the configuration file as commands
+// of the root command. Invalid aliases and those that would hide a command
+// are skipped with a warning.
+func registerAliases(file *config.File) {
+	var names []string
+	for _, key := range file.Keys() {
+		if name, ok := strings.CutPrefix(key, "alias."); ok {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		expansion, _ := file.Get("alias." + name)
+		if err := checkAlias(name, expansion); err != nil {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("Warning: alias %s skipped: %v", name, err))
+			continue
+		}
+		rootCmd.AddCommand(aliasCommand(name, expansion))
+	}
+}
+
+// checkAlias reports whether an alias can be defined: its command line
+// must parse and its name must not be taken by a command.
+func checkAlias(name, expansion string) error {
+	if _, err := splitArgs(expansion); err != nil {
+		return err
+	}
+	if c, _, err := rootCmd.Find([]string{name}); err == nil && c != rootCmd && c.Annotations[aliasAnnotation] == "" {
+		return i18n.Errorf("there is a command named %s", name)
+	}
+	return nil
+}
+
+// aliasAnnotation marks the commands of aliases, holding their command line.
+const aliasAnnotation = "alias"
+
+// aliasCommand returns the command of an alias. Flags are not parsed by it
+// but passed on with the other arguments.
+func aliasCommand(name, expansion string) *cobra.Command {
+	return &cobra.Command{
+		Use:                name + " [args]...",
+		Short:              i18n.Sprintf("Alias for: %s", expansion),
+		Long:               i18n.Sprintf("Alias for: book %s", expansion) + "\n\n" + aliasHelp,
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{aliasAnnotation: expansion},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && (args[0] == "--help" || args[0] == "-h") {
+				return cmd.Help()
+			}
+			line, err := expandAlias(expansion, args)
+			if err != nil {
+				return usageError{i18n.Errorf("alias %s: %w", name, err)}
+			}
+			if aliasDepth >= maxAliasDepth {
+				return usagef("alias %s: aliases expand into each other more than %d times", name, maxAliasDepth)
+			}
+			aliasDepth++
+			defer func() { aliasDepth-- }()
+			_, err = execute(line)
+			return err
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			line, err := expandAlias(expansion, args)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			target, targetArgs, err := rootCmd.Find(line)
+			if err != nil || target.ValidArgsFunction == nil || target.ParseFlags(targetArgs) != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return target.ValidArgsFunction(target, target.Flags().Args(), toComplete)
+		},
+	}
+}
+
+// expandAlias returns the command line an alias runs with args, see
+// aliasHelp.
+func expandAlias(expansion string, args []string) ([]string, error) {
+	words, err := splitArgs(expansion)
+	if err != nil {
+		return nil, err
+	}
+
+	var line []string
+	referenced := false
+	for _, word := range words {
+		if word == "$@" {
+			line = append(line, args...)
+			referenced = true
+			continue
+		}
+		var sb strings.Builder
+		var quote byte // of the quoted string of a filter expression at i, if any
+		for i := 0; i < len(word); i++ {
+			if word[i] != '$' || i+1 == len(word) {
+				c := word[i]
+				switch {
+				case c == '\\' && quote != 0 && i+1 < len(word):
+					sb.WriteByte(c)
+					i++
+					c = word[i]
+				case c == quote:
+					quote = 0
+				case quote == 0 && (c == '\'' || c == '"'):
+					quote = c
+				}
+				sb.WriteByte(c)
+				continue
+			}
+			switch next := word[i+1]; {
+			case next == '$':
+				sb.WriteByte('$')
+			case next == '@':
+				sb.WriteString(quoteArg(strings.Join(args, " "), quote))
+				referenced = true
+			case next >= '1' && next <= '9':
+				n, _ := strconv.Atoi(string(next))
+				if n > len(args) && n == 1 {
+					return nil, i18n.Errorf("needs an argument")
+				}
+				if n > len(args) {
+					return nil, i18n.Errorf("needs at least %d arguments", n)
+				}
+				sb.WriteString(quoteArg(args[n-1], quote))
+				referenced = true
+			default:
+				sb.WriteByte('$')
+				continue
+			}
+			i++
+		}
+		line = append(line, sb.String())
+	}
+	if !referenced {
+		line = append(line, args...)
+	}
+	if len(line) == 0 {
+		return nil, i18n.Errorf("runs no command")
+	}
+	return line, nil
+}
+
+// quoteArg escapes arg for the quoted string of a filter expression it is
+// substituted into, see lexString in package filter. Outside quotes, when
+// quote is 0, arg is kept as it is.
+func quoteArg(arg string, quote byte) string {
+	if quote == 0 {
+		return arg
+	}
+	q := string(quote)
+	return strings.NewReplacer(`\`, `\\`, q, `\`+q).Replace(arg)
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var authorCmd = &cobra.Command{
+	Use:   "author",
+	Short: "Manage author profiles and aliases",
+}
+
+var authorListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List authors by sort name",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewAuthorRepository(db)
+		authors, err := repo.GetAllAuthors()
+		if err != nil {
+			return i18n.Errorf("failed to find authors: %w", err)
+		}
+
+		if authors == nil {
+			authors = []models.Author{}
+		}
+		return render(cmd, authorList(authors))
+	},
+}
+
+var authorShowCmd = &cobra.Command{
+	Use:               "show <id|name>",
+	Short:             "Show an author profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePositional(completeAuthors),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewAuthorRepository(db)
+		author, err := findAuthor(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find author: %w", err)
+		}
+
+		return render(cmd, authorProfile{author})
+	},
+}
+
+var authorUpdateCmd = &cobra.Command{
+	Use:               "update <id|name>",
+	Short:             "Update an author profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePositional(completeAuthors),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewAuthorRepository(db)
+		author, err := findAuthor(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find author: %w", err)
+		}
+
+		if cmd.Flags().Changed("name") {
+			author.Name, _ = cmd.Flags().GetString("name")
+		}
+		if cmd.Flags().Changed("sort-name") {
+			author.SortName, _ = cmd.Flags().GetString("sort-name")
+		}
+		if cmd.Flags().Changed("born") {
+			author.BirthYear, _ = cmd.Flags().GetInt("born")
+		}
+		if cmd.Flags().Changed("died") {
+			author.DeathYear, _ = cmd.Flags().GetInt("died")
+		}
+		if cmd.Flags().Changed("country") {
+			author.Country, _ = cmd.Flags().GetString("country")
+		}
+
+		if err := repo.UpdateAuthor(author); err != nil {
+			return i18n.Errorf("failed to update author: %w", err)
+		}
+		return printMessage(cmd, 0, "Author with ID %d updated successfully", author.ID)
+	},
+}
+
+var authorAliasCmd = &cobra.Command{
+	Use:               "alias <id|name> <alias>...",
+	Short:             "Add pen names or spelling variants of an author",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completePositional(completeAuthors),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewAuthorRepository(db)
+		author, err := findAuthor(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find author: %w", err)
+		}
+
+		for _, alias := range args[1:] {
+			if err := repo.AddAlias(author.ID, alias); err != nil {
+				return i18n.Errorf("failed to add alias: %w", err)
+			}
+		}
+		return printMessage(cmd, 0, "Aliases for author with ID %d added successfully", author.ID)
+	},
+}
+
+var authorMergeCmd = &cobra.Command{
+	Use:   "merge <a> <b>",
+	Short: "Merge author b into author a",
+	Long: `Merge author b into author a.
+
+All books and aliases of b are moved to a, missing profile fields of a are
+filled in from b, and b is deleted. Authors are given by ID or any name.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completePositional(completeAuthors, completeAuthors),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewAuthorRepository(db)
+		keep, err := findAuthor(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find author: %w", err)
+		}
+		dup, err := findAuthor(repo, args[1])
+		if err != nil {
+			return i18n.Errorf("failed to find author: %w", err)
+		}
+
+		if err := repo.MergeAuthors(keep.ID, dup.ID); err != nil {
+			return i18n.Errorf("failed to merge authors: %w", err)
+		}
+		return printMessage(cmd, 0, "Author %q merged into %q", dup.Name, keep.Name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authorCmd)
+	authorCmd.AddCommand(authorListCmd, authorShowCmd, authorUpdateCmd, authorAliasCmd, authorMergeCmd)
+
+	authorUpdateCmd.Flags().String("name", "", "Canonical display name")
+	authorUpdateCmd.Flags().String("sort-name", "", `Sort name, e.g. "Tolkien, J. R. R."`)
+	authorUpdateCmd.Flags().Int("born", 0, "Birth year")
+	authorUpdateCmd.Flags().Int("died", 0, "Death year")
+	authorUpdateCmd.Flags().String("country", "", "Country")
+}
+
+type authorList []models.Author
+
+func (l authorList) WriteTable(out io.Writer) error {
+	if len(l) == 0 {
+		_, err := fmt.Fprintln(out, i18n.T("No authors found"))
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	writeHeader(w, "ID", "SORT NAME", "ALIASES")
+	for _, a := range l {
+		fmt.Fprintf(w, "%d\t%s\t%s\t\n", a.ID, a.SortName, strings.Join(otherAliases(a), "; "))
+	}
+	return w.Flush()
+}
+
+type authorProfile struct {
+	models.Author
+}
+
+func (p authorProfile) WriteTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	writeField(w, "ID", p.ID)
+	writeField(w, "Name", p.Name)
+	writeField(w, "Sort name", p.SortName)
+	writeField(w, "Aliases", strings.Join(otherAliases(p.Author), "; "))
+	writeField(w, "Lived", lifespan(p.Author))
+	writeField(w, "Country", p.Country)
+	return w.Flush()
+}
+
+// findAuthor looks an author up by numeric ID or by any of its names.
+func findAuthor(repo *repository.AuthorRepository, arg string) (models.Author, error) {
+	if id, err := strconv.Atoi(arg); err == nil {
+		return repo.GetAuthorByID(id)
+	}
+	return repo.GetAuthorByName(arg)
+}
+
+// otherAliases returns the aliases of an author except its display name.
+func otherAliases(a models.Author) []string {
+	var aliases []string
+	for _, alias := range a.Aliases {
+		if alias != a.Name {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+func lifespan(a models.Author) string {
+	switch {
+	case a.BirthYear == 0 && a.DeathYear == 0:
+		return ""
+	case a.DeathYear == 0:
+		return fmt.Sprintf("%d–", a.BirthYear)
+	case a.BirthYear == 0:
+		return fmt.Sprintf("?–%d", a.DeathYear)
+	}
+	return fmt.Sprintf("%d–%d", a.BirthYear, a.DeathYear)
+}
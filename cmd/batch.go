@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <file|->",
+	Short: "Run commands from a file in one transaction",
+	Long: `Run commands from a file, or from stdin with -, in one transaction.
+
+Every line holds one command as it would follow "book" on the command
+line, quoted like in a shell; the leading "book" may be kept. Empty lines
+and lines starting with # are skipped:
+
+    # shelve the new arrivals
+    add --title "The Hobbit" --author "J. R. R. Tolkien" --year 1937
+    update 12-14 --location "living room"
+    delete 7
+
+If any line fails, the batch stops with the line number and none of its
+changes are kept. Flags given to batch itself, such as --output, apply to
+every line. Commands never ask questions in a batch.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lines, err := readBatch(args[0])
+		if err != nil {
+			return i18n.Errorf("failed to read batch: %w", err)
+		}
+
+		b, err := db.BeginBatch()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		batchMode = true
+
+		flags := saveFlags(rootCmd)
+		for _, line := range lines {
+			flags.restore()
+			if _, err := execute(line.args); err != nil {
+				b.Rollback()
+				return i18n.Errorf("line %d: %w; no changes were made", line.number, err)
+			}
+		}
+		running = true
+		flags.restore()
+		if err := b.Commit(); err != nil {
+			return i18n.Errorf("failed to commit batch: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+}
+
+// batchMode is set while a batch runs.
+var batchMode bool
+
+type batchLine struct {
+	number int
+	args   []string
+}
+
+// readBatch reads and splits the lines of a batch, so that a syntax error
+// or an unknown command anywhere stops the batch before it changes anything.
+func readBatch(name string) ([]batchLine, error) {
+	var in io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var lines []batchLine
+	scanner := bufio.NewScanner(in)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		args, err := splitArgs(text)
+		if err != nil {
+			return nil, usagef("line %d: %v", n, err)
+		}
+		if args[0] == "book" {
+			args = args[1:]
+		}
+		if len(args) > 0 && (args[0] == "batch" || args[0] == "interactive") {
+			return nil, usagef("line %d: %s cannot run in a batch", n, args[0])
+		}
+		if _, _, err := rootCmd.Find(args); err != nil {
+			return nil, usagef("line %d: %v", n, err)
+		}
+		lines = append(lines, batchLine{n, args})
+	}
+	return lines, scanner.Err()
+}
+
+// splitArgs splits a command line into arguments like a POSIX shell does:
+// at unquoted blanks, with 'single quotes' taken literally and backslash
+// escapes outside them.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, i18n.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, i18n.Errorf("line ends with a backslash")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// savedFlags are the values of all flags of a command tree at one moment.
+// Cobra keeps flag values between executions, so each batch line has to
+// start from them again.
+type savedFlags struct {
+	root   *cobra.Command
+	values map[*pflag.Flag]savedFlag
+}
+
+type savedFlag struct {
+	value   string
+	changed bool
+}
+
+func saveFlags(root *cobra.Command) savedFlags {
+	s := savedFlags{root: root, values: make(map[*pflag.Flag]savedFlag)}
+	visitFlags(root, func(f *pflag.Flag) {
+		s.values[f] = savedFlag{f.Value.String(), f.Changed}
+	})
+	return s
+}
+
+// restore resets every flag to its saved value. Flags created since, such
+// as the help flags cobra adds on demand, go back to their defaults.
+func (s savedFlags) restore() {
+	visitFlags(s.root, func(f *pflag.Flag) {
+		saved, ok := s.values[f]
+		if !ok {
+			saved = savedFlag{f.DefValue, false}
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			values, _ := readSlice(saved.value)
+			slice.Replace(values)
+		} else {
+			f.Value.Set(saved.value)
+		}
+		f.Changed = saved.changed
+	})
+}
+
+// readSlice parses the "[a,b]" form in which slice flags print their value.
+func readSlice(s string) ([]string, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if s == "" {
+		return nil, nil
+	}
+	return strings.Split(s, ","), nil
+}
+
+func visitFlags(cmd *cobra.Command, fn func(*pflag.Flag)) {
+	cmd.Flags().VisitAll(fn)
+	cmd.PersistentFlags().VisitAll(fn)
+	for _, c := range cmd.Commands() {
+		visitFlags(c, fn)
+	}
+}
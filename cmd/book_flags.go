@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/validation"
+	"github.com/spf13/cobra"
+	"golang.org/x/text/language"
+)
+
+// addBookFieldFlags registers the optional book field flags shared by add and update.
+func addBookFieldFlags(cmd *cobra.Command) {
+	cmd.Flags().String("publisher", "", "Publisher of this edition")
+	cmd.Flags().StringP("language", "l", "", "Language of this edition (BCP-47, e.g. ru, en-GB)")
+	cmd.Flags().String("original-language", "", "Language the book was translated from (BCP-47)")
+	cmd.Flags().String("original-title", "", "Title of the original work")
+	cmd.Flags().String("location", "", "Where the copy is kept")
+	cmd.Flags().String("format", "", "Format of the copy ("+strings.Join(models.Formats, ", ")+")")
+	cmd.Flags().String("purchased", "", "Purchase date (YYYY-MM-DD)")
+	cmd.Flags().String("price", "", "Purchase price, e.g. 12.50")
+	cmd.Flags().String("currency", "", "Currency of price and value (ISO 4217, e.g. EUR)")
+	cmd.Flags().String("value", "", "Current estimated value, e.g. 30")
+	cmd.Flags().String("notes", "", "Free-form notes")
+	cmd.Flags().String("isbn", "", "ISBN-10 or ISBN-13, hyphens allowed")
+	cmd.Flags().Int("pages", 0, "Number of pages")
+	cmd.Flags().String("finished", "", "Date the book was last read (YYYY-MM-DD)")
+
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(models.Formats, cobra.ShellCompDirectiveNoFileComp))
+	for _, name := range []string{"publisher", "language", "original-language", "location", "currency"} {
+		cmd.RegisterFlagCompletionFunc(name, completeFieldValues(strings.ReplaceAll(name, "-", "_")))
+	}
+}
+
+// bookFieldFlagsChanged reports whether any field flag was given on the
+// command line.
+func bookFieldFlagsChanged(cmd *cobra.Command) bool {
+	for _, name := range []string{"publisher", "language", "original-language", "original-title",
+		"location", "format", "purchased", "price", "currency", "value", "notes", "isbn", "pages", "finished"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBookFieldFlags copies the field flags that were set on the command
+// line into book, in the canonical form of each field. For add they may also
+// have a default from the configuration, see settingKeys; update has none,
+// so it only changes what the command line says. Checking the values is
+// left to package validation, which the repository applies.
+func applyBookFieldFlags(cmd *cobra.Command, book *models.Book) error {
+	flags := cmd.Flags()
+	if flagGiven(cmd, "publisher") {
+		book.Publisher, _ = flags.GetString("publisher")
+	}
+	if flagGiven(cmd, "original-title") {
+		book.OriginalTitle, _ = flags.GetString("original-title")
+	}
+	if flagGiven(cmd, "language") {
+		value, _ := flags.GetString("language")
+		book.Language = canonicalLanguage(value)
+	}
+	if flagGiven(cmd, "original-language") {
+		value, _ := flags.GetString("original-language")
+		book.OriginalLanguage = canonicalLanguage(value)
+	}
+	if flagGiven(cmd, "notes") {
+		book.Notes, _ = flags.GetString("notes")
+	}
+	if flagGiven(cmd, "location") {
+		book.Location, _ = flags.GetString("location")
+	}
+	if flagGiven(cmd, "format") {
+		value, _ := flags.GetString("format")
+		book.Format = strings.ToLower(value)
+	}
+	if flagGiven(cmd, "purchased") {
+		book.PurchaseDate, _ = flags.GetString("purchased")
+	}
+	if flagGiven(cmd, "price") {
+		value, _ := flags.GetString("price")
+		amount, err := models.ParseAmount(value)
+		if err != nil {
+			return usageError{err}
+		}
+		book.PurchasePrice = amount
+	}
+	if flagGiven(cmd, "value") {
+		value, _ := flags.GetString("value")
+		amount, err := models.ParseAmount(value)
+		if err != nil {
+			return usageError{err}
+		}
+		book.EstimatedValue = amount
+	}
+	if flagGiven(cmd, "currency") {
+		value, _ := flags.GetString("currency")
+		book.Currency = strings.ToUpper(value)
+	}
+	if flagGiven(cmd, "isbn") {
+		value, _ := flags.GetString("isbn")
+		book.ISBN = validation.NormalizeISBN(value)
+	}
+	if flagGiven(cmd, "pages") {
+		book.Pages, _ = flags.GetInt("pages")
+	}
+	if flagGiven(cmd, "finished") {
+		book.ReadDate, _ = flags.GetString("finished")
+	}
+	return nil
+}
+
+// canonicalLanguage returns the canonical form of a BCP-47 tag, e.g. "en-GB"
+// for "en_gb". Invalid tags are returned unchanged for validation to report.
+func canonicalLanguage(value string) string {
+	tag, err := language.Parse(value)
+	if value == "" || err != nil {
+		return value
+	}
+	return tag.String()
+}
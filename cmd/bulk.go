@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+// bulkHelp documents the book arguments of commands acting on several books.
+const bulkHelp = `Books are given by ID, by ranges of IDs such as 10-25, by - to read IDs
+and ranges from stdin, or by a filter expression with --where (see book
+list --help). All books are changed in one transaction: if one fails,
+none is changed. Changing more books than --confirm-above requires --yes.`
+
+// addBulkFlags registers the flags of commands acting on several books.
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().String("where", "", "Act on the books matching a filter expression")
+	cmd.Flags().Bool("yes", false, "Confirm changing more books than --confirm-above")
+	cmd.Flags().Int("confirm-above", 10, "Number of books above which --yes is required")
+}
+
+// bulkBookIDs resolves the book arguments of a bulk command to book IDs, in
+// order and without repetitions. With byTitle, a single book may also be
+// given by title or author, see findBookID. single reports whether exactly
+// one book was named, so the command can keep its single-book messages.
+func bulkBookIDs(cmd *cobra.Command, repo *repository.BookRepository, args []string, byTitle bool) (ids []int, single bool, err error) {
+	where, _ := cmd.Flags().GetString("where")
+	switch {
+	case where != "" && len(args) > 0:
+		return nil, false, usagef("give either books or --where, not both")
+	case where != "":
+		books, err := repo.FindBooks(where)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, book := range books {
+			ids = append(ids, book.ID)
+		}
+		return ids, false, nil
+	case len(args) == 0:
+		return nil, false, usagef("no books given")
+	case len(args) == 1 && args[0] != "-" && !isIDRange(args[0]):
+		if byTitle {
+			id, err := findBookID(repo, args[0])
+			return []int{id}, true, err
+		}
+		ids, err := parseBookIDs(repo, args[0])
+		return ids, true, err
+	}
+
+	seen := make(map[int]bool)
+	add := func(arg string) error {
+		found, err := parseBookIDs(repo, arg)
+		for _, id := range found {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return err
+	}
+	for _, arg := range args {
+		if arg != "-" {
+			if err := add(arg); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Split(bufio.ScanWords)
+		for scanner.Scan() {
+			if err := add(scanner.Text()); err != nil {
+				return nil, false, err
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, false, i18n.Errorf("failed to read IDs from stdin: %v", err)
+		}
+	}
+	return ids, false, nil
+}
+
+// parseBookIDs parses an ID or a range of IDs. A range stands for the books
+// in it, so IDs of deleted books are skipped; a single ID is taken as is.
+func parseBookIDs(repo *repository.BookRepository, arg string) ([]int, error) {
+	if !isIDRange(arg) {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, usagef("invalid ID %q: expected a number or a range such as 10-25", arg)
+		}
+		return []int{id}, nil
+	}
+
+	from, to, _ := strings.Cut(arg, "-")
+	first, _ := strconv.Atoi(from)
+	last, _ := strconv.Atoi(to)
+	if first > last {
+		return nil, usagef("invalid range %q: %d is greater than %d", arg, first, last)
+	}
+	books, err := repo.FindBooks(fmt.Sprintf("id >= %d and id <= %d", first, last))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+	return ids, nil
+}
+
+func isIDRange(arg string) bool {
+	from, to, ok := strings.Cut(arg, "-")
+	if !ok {
+		return false
+	}
+	_, err1 := strconv.Atoi(from)
+	_, err2 := strconv.Atoi(to)
+	return err1 == nil && err2 == nil
+}
+
+// confirmBulk refuses to change more than --confirm-above books without
+// --yes. one and other are the refusal for one and for n books.
+func confirmBulk(cmd *cobra.Command, n int, one, other string) error {
+	limit, _ := cmd.Flags().GetInt("confirm-above")
+	yes, _ := cmd.Flags().GetBool("yes")
+	if n > limit && !yes {
+		return usageError{errors.New(i18n.N(n, one, other, n))}
+	}
+	return nil
+}
+
+// formatIDs writes IDs compactly, runs of consecutive IDs as ranges:
+// "1-4, 7, 9-10".
+func formatIDs(ids []int) string {
+	var parts []string
+	for i := 0; i < len(ids); {
+		j := i
+		for j+1 < len(ids) && ids[j+1] == ids[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", ids[i], ids[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(ids[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printBulkMessage reports the outcome of a command that changed several
+// books, e.g. "3 books deleted (IDs 1-3)". one and other are the message
+// for one and for several books, taking the number and the IDs.
+func printBulkMessage(cmd *cobra.Command, ids []int, one, other string) error {
+	if len(ids) == 0 {
+		return render(cmd, message{Message: i18n.T("No books matched")})
+	}
+	return render(cmd, message{IDs: ids, Message: i18n.N(len(ids), one, other, len(ids), formatIDs(ids))})
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/filter"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+// The functions below complete arguments and flag values from the library
+// for the completion scripts of every shell, see book completion --help.
+
+// completionDB opens the library for a completion function. Completion
+// runs without the command's hooks, so the settings are applied here; a
+// missing database is not created.
+func completionDB(cmd *cobra.Command) (*sql.DB, error) {
+	if err := applySettings(cmd, nil); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(db.Path); err != nil {
+		return nil, err
+	}
+	return db.InitDB()
+}
+
+// completeBooks completes book IDs, described by title and author. Books
+// already given are not offered again.
+func completeBooks(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	db, err := completionDB(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	defer db.Close()
+
+	books, err := repository.NewBookRepository(db).GetAllBooks()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []cobra.Completion
+	for _, book := range books {
+		id := strconv.Itoa(book.ID)
+		if strings.HasPrefix(id, toComplete) && !slices.Contains(args, id) {
+			completions = append(completions, cobra.CompletionWithDesc(id, fmt.Sprintf("%s (%s)", book.Title, book.Author)))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeAuthors completes author IDs, described by name.
+func completeAuthors(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	db, err := completionDB(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	defer db.Close()
+
+	authors, err := repository.NewAuthorRepository(db).GetAllAuthors()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []cobra.Completion
+	for _, author := range authors {
+		id := strconv.Itoa(author.ID)
+		if strings.HasPrefix(id, toComplete) && !slices.Contains(args, id) {
+			completions = append(completions, cobra.CompletionWithDesc(id, author.Name))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeFieldValues completes the values a book field has in the
+// library, the most common first, described by their number of books.
+func completeFieldValues(field string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		db, err := completionDB(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		defer db.Close()
+
+		values, counts, err := repository.NewBookRepository(db).FieldValues(field)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var completions []cobra.Completion
+		for i, value := range values {
+			if strings.HasPrefix(strings.ToLower(value), strings.ToLower(toComplete)) {
+				completions = append(completions, cobra.CompletionWithDesc(value, i18n.N(counts[i], "%d book", "%d books", counts[i])))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+}
+
+// completeViews completes the names of saved views, described by their
+// filter expression.
+func completeViews(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	db, err := completionDB(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	defer db.Close()
+
+	views, err := repository.NewBookRepository(db).GetViews()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []cobra.Completion
+	for _, view := range views {
+		if strings.HasPrefix(view.Name, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(view.Name, view.Where))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeList completes the last item of a comma separated list.
+func completeList(items ...string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		done, _ := cutLast(toComplete, ",")
+		var completions []cobra.Completion
+		for _, item := range items {
+			completions = append(completions, done+item)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// completeSort completes a sort specification, see book list.
+func completeSort(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var fields []string
+	for _, field := range filter.Fields() {
+		fields = append(fields, field, "-"+field)
+	}
+	return completeList(fields...)(cmd, args, toComplete)
+}
+
+// cutLast splits s after the last sep.
+func cutLast(s, sep string) (before, after string) {
+	i := strings.LastIndex(s, sep)
+	return s[:i+len(sep)], s[i+len(sep):]
+}
+
+// completePositional completes each argument with the function at its
+// position. A nil function completes file names; arguments past the last
+// function are not completed.
+func completePositional(funcs ...cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) >= len(funcs) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if funcs[len(args)] == nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return funcs[len(args)](cmd, args, toComplete)
+	}
+}
+
+// completeSettings completes the names of settings, see book config.
+func completeSettings(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var completions []cobra.Completion
+	for _, s := range settings() {
+		completions = append(completions, cobra.CompletionWithDesc(s.key, s.flag.Usage))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSettingValue completes the value of the setting named by the
+// first argument as its flag would be completed.
+func completeSettingValue(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	s, err := findSetting(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	if s.flag.Value.Type() == "bool" {
+		return []cobra.Completion{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	if complete, ok := s.cmd.GetFlagCompletionFunc(s.flag.Name); ok {
+		return complete(cmd, nil, toComplete)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
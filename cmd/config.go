@@ -0,0 +1,389 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/config"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change the settings in the configuration file",
+	Long: `Show and change the settings in the configuration file.
+
+Some flags can be given a default of your own: the output and database of
+every command, the theme of the TUI and the usual choices of a few
+commands, all listed by book config list. A setting is named after the
+command and the flag, e.g. list.sort for "book list --sort" and
+add.status for "book add --status"; flags of every command are named
+without a command. In the configuration file the command is a TOML table:
+
+    database = "~/books/books.db"
+    output = "table"
+    date-format = "DD.MM.YYYY"
+
+    [add]
+    status = "read"
+
+    [list]
+    sort = "author,year"
+
+    [interactive]
+    theme = "light"
+
+The file is $XDG_CONFIG_HOME/book/config.toml (usually
+~/.config/book/config.toml), or the one given with --config or
+$BOOK_CONFIG. Settings can also be given as environment variables named
+BOOK_ and the setting in capitals with _ for . and -, e.g. BOOK_LIST_SORT.
+A flag on the command line wins over the environment, which wins over the
+configuration file, which wins over the built-in default.
+
+Flags that confirm an action, such as --yes, or that say which books a
+command changes have no setting, so that no setting can skip a question
+or change every book.
+
+` + aliasHelp + `
+
+Aliases are set like settings, e.g. book config set alias.unread "list
+--where 'status = unread'".`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get <setting>",
+	Short:             "Print the value of a setting",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePositional(completeSettings),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.HasPrefix(args[0], "alias.") {
+			value, ok := loadedConfig.Get(args[0])
+			if !ok {
+				return notFoundf("no %s in %s", args[0], loadedConfig.Path)
+			}
+			fmt.Println(value)
+			return nil
+		}
+		s, err := findSetting(args[0])
+		if err != nil {
+			return i18n.Errorf("failed to read setting: %w", err)
+		}
+		value, _ := s.lookup(loadedConfig)
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <setting> <value>",
+	Short:             "Change a setting in the configuration file",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completePositional(completeSettings, completeSettingValue),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if name, ok := strings.CutPrefix(args[0], "alias."); ok {
+			if err := checkAlias(name, args[1]); err != nil {
+				return usagef("invalid alias %s: %v", name, err)
+			}
+			if err := loadedConfig.Set(args[0], args[1]); err != nil {
+				return usagef("failed to set alias: %w", err)
+			}
+			if err := loadedConfig.Save(); err != nil {
+				return i18n.Errorf("failed to save configuration: %w", err)
+			}
+			return printMessage(cmd, 0, "%s set to %s in %s", args[0], args[1], loadedConfig.Path)
+		}
+		s, err := findSetting(args[0])
+		if err != nil {
+			return i18n.Errorf("failed to change setting: %w", err)
+		}
+		value, err := s.parse(args[1])
+		if err != nil {
+			return usagef("invalid value for %s: %v", s.key, err)
+		}
+		if err := loadedConfig.Set(s.key, value); err != nil {
+			return usagef("failed to change setting: %w", err)
+		}
+		if err := loadedConfig.Save(); err != nil {
+			return i18n.Errorf("failed to save configuration: %w", err)
+		}
+		return printMessage(cmd, 0, "%s set to %s in %s", s.key, args[1], loadedConfig.Path)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings with their values and where they come from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var list configList
+		for _, s := range settings() {
+			value, source := s.lookup(loadedConfig)
+			list = append(list, configEntry{Key: s.key, Value: value, Source: source})
+		}
+		for _, key := range loadedConfig.Keys() {
+			if _, err := findSetting(key); err != nil {
+				value, _ := loadedConfig.Get(key)
+				source := "config (unknown setting)"
+				if strings.HasPrefix(key, "alias.") {
+					source = "config (alias)"
+				}
+				list = append(list, configEntry{Key: key, Value: value, Source: source})
+			}
+		}
+		return render(cmd, list)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("config", "", "Configuration file (default $XDG_CONFIG_HOME/book/config.toml)")
+	rootCmd.PersistentFlags().String("database", db.DefaultPath, "SQLite database file")
+	rootCmd.MarkPersistentFlagFilename("config", "toml")
+	rootCmd.MarkPersistentFlagFilename("database", "db", "sqlite", "sqlite3")
+	rootCmd.PersistentPreRunE = applySettings
+
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+}
+
+// loadedConfig is the configuration file read before every command.
+var loadedConfig *config.File
+
+// configuredFlags are the flags applySettings gave a value from the
+// environment or the configuration file. They are not marked as changed,
+// which stays reserved for flags given on the command line.
+var configuredFlags map[*pflag.Flag]bool
+
+// applySettings gives the flags the command line left unset their values
+// from the environment or the configuration file, and applies the global
+// settings.
+func applySettings(cmd *cobra.Command, args []string) error {
+	flag, _ := cmd.Flags().GetString("config")
+	var err error
+	if loadedConfig, err = loadConfig(flag); err != nil {
+		return err
+	}
+
+	var errs []string
+	configuredFlags = make(map[*pflag.Flag]bool)
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		s, ok := flagSetting(cmd, f)
+		if !ok || f.Changed || excludedByChangedFlag(cmd, f) {
+			return
+		}
+		value, source := s.lookup(loadedConfig)
+		if source == "default" {
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			errs = append(errs, i18n.Sprintf("invalid %s from %s: %v", s.key, source, err))
+			return
+		}
+		configuredFlags[f] = true
+	})
+	if len(errs) > 0 {
+		return usagef("%s", strings.Join(errs, "; "))
+	}
+
+	database, _ := cmd.Flags().GetString("database")
+	db.Path = expandHome(database)
+	return applyDateFormat(cmd)
+}
+
+// flagGiven reports whether the flag name of cmd was given on the command
+// line or has a value from the environment or the configuration file.
+func flagGiven(cmd *cobra.Command, name string) bool {
+	f := cmd.Flags().Lookup(name)
+	return f != nil && (f.Changed || configuredFlags[f])
+}
+
+// loadConfig reads the configuration file selected by flag, see
+// configPath.
+func loadConfig(flag string) (*config.File, error) {
+	path, err := configPath(flag)
+	if err != nil {
+		return nil, err
+	}
+	file, err := config.Load(path)
+	if err != nil {
+		return nil, usagef("invalid configuration: %w", err)
+	}
+	return file, nil
+}
+
+// configPath returns the configuration file to read: the one given with
+// --config as flag, else $BOOK_CONFIG, else the default one.
+func configPath(flag string) (string, error) {
+	path := flag
+	if path == "" {
+		path = os.Getenv("BOOK_CONFIG")
+	}
+	if path == "" {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			return "", i18n.Errorf("no configuration directory: %v", err)
+		}
+	}
+	return expandHome(path), nil
+}
+
+// excludedByChangedFlag reports whether a flag in a mutually exclusive
+// group with f was given on the command line, so that a default for f
+// must not be applied.
+func excludedByChangedFlag(cmd *cobra.Command, f *pflag.Flag) bool {
+	for _, group := range f.Annotations["cobra_annotation_mutually_exclusive"] {
+		for _, name := range strings.Fields(group) {
+			if other := cmd.Flags().Lookup(name); other != nil && other != f && other.Changed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// settingKeys are the flags whose defaults can be changed in the
+// configuration file or the environment. Confirmations and the fields and
+// selections of update, delete and similar commands are left out on
+// purpose: a default for them would apply to every run without a word.
+var settingKeys = []string{
+	"database", "output", "template", "date-format", "lang",
+	"interactive.theme",
+	"add.status", "add.strict", "add.format", "add.language", "add.location", "add.publisher", "add.currency",
+	"list.sort", "list.limit",
+	"view.run.limit",
+	"search.limit", "search.fuzzy",
+	"pick.candidates", "pick.recent-days",
+	"update.confirm-above", "delete.confirm-above",
+}
+
+// A setting is a flag whose default can be changed in the configuration
+// file or the environment.
+type setting struct {
+	key  string
+	cmd  *cobra.Command // command defining the flag
+	flag *pflag.Flag
+}
+
+// settings returns the settings of all commands, sorted by key.
+func settings() []setting {
+	var list []setting
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+			if s, ok := flagSetting(cmd, f); ok {
+				list = append(list, s)
+			}
+		})
+		for _, c := range cmd.Commands() {
+			visit(c)
+		}
+	}
+	visit(rootCmd)
+	slices.SortFunc(list, func(a, b setting) int { return strings.Compare(a.key, b.key) })
+	return slices.CompactFunc(list, func(a, b setting) bool { return a.key == b.key })
+}
+
+func findSetting(key string) (setting, error) {
+	for _, s := range settings() {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, i18n.Errorf("unknown setting %q (see book config list)", key)
+}
+
+// flagSetting returns the setting of a flag of cmd, which may be inherited
+// from a parent command. Only the flags of settingKeys have a setting.
+func flagSetting(cmd *cobra.Command, f *pflag.Flag) (setting, bool) {
+	owner := cmd
+	for owner.LocalFlags().Lookup(f.Name) != f && owner.HasParent() {
+		owner = owner.Parent()
+	}
+	path := strings.Fields(owner.CommandPath())[1:]
+	key := strings.Join(append(path, f.Name), ".")
+	if !slices.Contains(settingKeys, key) {
+		return setting{}, false
+	}
+	return setting{key: key, cmd: owner, flag: f}, true
+}
+
+// env returns the name of the environment variable of the setting, e.g.
+// BOOK_LIST_SORT for list.sort.
+func (s setting) env() string {
+	return "BOOK_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(s.key))
+}
+
+// lookup returns the value of the setting when its flag is not given and
+// where it comes from: "env", "config" or "default".
+func (s setting) lookup(file *config.File) (value, source string) {
+	if value, ok := os.LookupEnv(s.env()); ok {
+		return value, "env"
+	}
+	if value, ok := file.Get(s.key); ok {
+		return value, "config"
+	}
+	value = s.flag.DefValue
+	if _, ok := s.flag.Value.(pflag.SliceValue); ok {
+		value, _ = strings.CutPrefix(strings.TrimSuffix(value, "]"), "[")
+	}
+	return value, "default"
+}
+
+// parse checks value for the type of the setting's flag and returns it as
+// it is written to the configuration file.
+func (s setting) parse(value string) (any, error) {
+	switch s.flag.Value.Type() {
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, i18n.Errorf("%q is not true or false", value)
+		}
+		return b, nil
+	case "int":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, i18n.Errorf("%q is not a whole number", value)
+		}
+		return n, nil
+	case "stringSlice":
+		return strings.Split(value, ","), nil
+	default:
+		return value, nil
+	}
+}
+
+type configEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // env, config or default
+}
+
+type configList []configEntry
+
+func (l configList) WriteTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	writeHeader(w, "SETTING", "VALUE", "SOURCE")
+	for _, e := range l {
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", e.Key, e.Value, i18n.T(e.Source))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/covers"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var coverCmd = &cobra.Command{
+	Use:   "cover",
+	Short: "Manage book cover images",
+}
+
+var coverSetCmd = &cobra.Command{
+	Use:               "set <id|title> <file>",
+	Short:             "Attach a cover image (png, jpeg, gif) to a book",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completePositional(completeBooks, nil),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer conn.Close()
+
+		repo := repository.NewBookRepository(conn)
+		id, err := findBookID(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+
+		name, err := covers.Store(db.CoversDir(), args[1])
+		if err != nil {
+			return i18n.Errorf("failed to store cover: %w", err)
+		}
+
+		if err := repo.SetCover(id, name); err != nil {
+			return i18n.Errorf("failed to set cover: %w", err)
+		}
+
+		return printMessage(cmd, id, "Cover for book with ID %d set successfully", id)
+	},
+}
+
+var coverExportCmd = &cobra.Command{
+	Use:   "export <id|title> [file]",
+	Short: "Copy the cover image of a book to a file",
+	Long: `Copy the cover image of a book to a file.
+
+Without a file name the cover is written to cover-<id>.<ext> in the
+current directory.`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completePositional(completeBooks, nil),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer conn.Close()
+
+		repo := repository.NewBookRepository(conn)
+		id, err := findBookID(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+
+		book, err := repo.GetBookByID(id)
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+		if book.Cover == "" {
+			return notFoundf("book with ID %d has no cover", id)
+		}
+
+		dst := fmt.Sprintf("cover-%d%s", id, filepath.Ext(book.Cover))
+		if len(args) == 2 {
+			dst = args[1]
+		}
+		if err := covers.Export(db.CoversDir(), book.Cover, dst); err != nil {
+			return i18n.Errorf("failed to export cover: %w", err)
+		}
+
+		return printMessage(cmd, id, "Cover for book with ID %d exported to %s", id, dst)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(coverCmd)
+	coverCmd.AddCommand(coverSetCmd)
+	coverCmd.AddCommand(coverExportCmd)
+}
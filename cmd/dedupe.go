@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe [<keep> <duplicate>...]",
+	Short: "Find books entered more than once and merge them",
+	Long: `Find books entered more than once and merge them.
+
+Without arguments, lists the groups of likely duplicates: books with the
+same ISBN (ISBN-10 and ISBN-13 compare equal), or without ISBN and with a
+similar title and author, spelling variants, typos and either script
+included. Editions with different ISBNs or languages are not duplicates.
+
+With --merge, asks for each group which book to keep and merges the others
+into it. Given books, merges the duplicates into the first one.
+
+Merging keeps the fields of the kept book and fills in those it lacks from
+the duplicates. Notes are joined, quotes and relations move over, a book
+read as any of the copies is read, and the duplicates are deleted. Books
+are given by ID, or by title or author as in show.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeBooks,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		merge, _ := cmd.Flags().GetBool("merge")
+		if len(args) == 1 {
+			return usagef("give the book to keep and at least one duplicate")
+		}
+		if merge && len(args) > 0 {
+			return usagef("--merge takes no books")
+		}
+		if merge && !interactive() {
+			return usagef("--merge needs a terminal; give the books to merge instead")
+		}
+
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewBookRepository(db)
+		if len(args) > 0 {
+			ids := make([]int, len(args))
+			for i, arg := range args {
+				if ids[i], err = findBookID(repo, arg); err != nil {
+					return i18n.Errorf("failed to find book: %w", err)
+				}
+			}
+			if err := repo.MergeBooks(ids[0], ids[1:]); err != nil {
+				return i18n.Errorf("failed to merge books: %w", err)
+			}
+			return render(cmd, message{ID: ids[0], IDs: ids[1:], Message: i18n.N(len(ids)-1,
+				"Book with ID %s merged into book with ID %d", "Books with IDs %s merged into book with ID %d", formatIDs(ids[1:]), ids[0])})
+		}
+
+		groups, err := repo.DuplicateGroups()
+		if err != nil {
+			return i18n.Errorf("failed to find duplicates: %w", err)
+		}
+		if groups == nil {
+			groups = [][]repository.Duplicate{}
+		}
+		if !merge {
+			return render(cmd, duplicateGroups{Groups: groups})
+		}
+
+		var merged []int
+		for i, group := range groups {
+			options := make([]string, len(group)+1)
+			for j, dup := range group {
+				options[j] = describeBook(dup)
+			}
+			options[len(group)] = i18n.T("Skip, these are different books")
+			keep, err := choose(i18n.Sprintf("Group %d of %d (%s), keep:", i+1, len(groups), i18n.T(group[len(group)-1].Reason)), options)
+			if err != nil {
+				return i18n.Errorf("failed to merge books: %w", err)
+			}
+			if keep == len(group) {
+				continue
+			}
+
+			var dupIDs []int
+			for j, dup := range group {
+				if j != keep {
+					dupIDs = append(dupIDs, dup.ID)
+				}
+			}
+			if err := repo.MergeBooks(group[keep].ID, dupIDs); err != nil {
+				return i18n.Errorf("failed to merge books: %w", err)
+			}
+			merged = append(merged, dupIDs...)
+		}
+		if len(merged) == 0 {
+			return printMessage(cmd, 0, "No books merged")
+		}
+		return printBulkMessage(cmd, merged, "%d book merged (IDs %s)", "%d books merged (IDs %s)")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+	dedupeCmd.Flags().BoolP("merge", "m", false, "Ask which book of each group to keep and merge the others into it")
+}
+
+// describeBook names a likely duplicate, e.g. in prompts and warnings.
+func describeBook(dup repository.Duplicate) string {
+	s := i18n.Sprintf("%s by %s (%d), ID %d", dup.Title, dup.Author, dup.PublishedYear, dup.ID)
+	if dup.ISBN != "" {
+		s += ", ISBN " + dup.ISBN
+	}
+	return s
+}
+
+// describeDuplicates lists the books a new book likely duplicates.
+func describeDuplicates(dups []repository.Duplicate) string {
+	descriptions := make([]string, len(dups))
+	for i, dup := range dups {
+		descriptions[i] = fmt.Sprintf("%s: %s", describeBook(dup), i18n.T(dup.Reason))
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+type duplicateGroups struct {
+	Groups [][]repository.Duplicate `json:"groups"`
+}
+
+// duplicateRow is one book of a group in the flat layout used by csv, tsv
+// and jsonl.
+type duplicateRow struct {
+	Group int `json:"group"`
+	repository.Duplicate
+}
+
+func (g duplicateGroups) Items() any {
+	rows := []duplicateRow{}
+	for i, group := range g.Groups {
+		for _, dup := range group {
+			rows = append(rows, duplicateRow{i + 1, dup})
+		}
+	}
+	return rows
+}
+
+func (g duplicateGroups) WriteTable(w io.Writer) error {
+	if len(g.Groups) == 0 {
+		_, err := fmt.Fprintln(w, i18n.T("No duplicates found"))
+		return err
+	}
+
+	for i, group := range g.Groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, i18n.Sprintf("Group %d:", i+1))
+		for _, dup := range group {
+			fmt.Fprintln(w, i18n.Sprintf("- ID: %d, Title: %s, Author: %s, Year: %d, ISBN: %s (%s)",
+				dup.ID, dup.Title, dup.Author, dup.PublishedYear, dup.ISBN, i18n.T(dup.Reason)))
+		}
+	}
+	fmt.Fprintln(w, "\n"+i18n.T("Merge them with: book dedupe --merge, or book dedupe <keep> <duplicate>..."))
+	return nil
+}
@@ -1,41 +1,48 @@
 package cmd
 
 import (
-	"fmt"
-	"log"
-	"strconv"
-
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
 	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
 	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
 	"github.com/spf13/cobra"
 )
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete <id>",
+	Use:   "delete <id|range|->...",
 	Short: "Delete a book by ID",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Delete books with their relations and quotes.
+
+` + bulkHelp,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeBooks,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		db, err := db.InitDB()
 		if err != nil {
-			log.Fatalf("Failed to connect to database: %v", err)
+			return i18n.Errorf("failed to connect to database: %w", err)
 		}
 		defer db.Close()
 
-		id, err := strconv.Atoi(args[0])
+		repo := repository.NewBookRepository(db)
+		ids, single, err := bulkBookIDs(cmd, repo, args, false)
 		if err != nil {
-			log.Fatalf("Invalid ID format: %v", err)
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+		if err := confirmBulk(cmd, len(ids), "this would delete %d book; add --yes to confirm", "this would delete %d books; add --yes to confirm"); err != nil {
+			return i18n.Errorf("failed to delete books: %w", err)
 		}
 
-		repo := repository.NewBookRepository(db)
-		err = repo.DeleteBook(id)
-		if err != nil {
-			log.Fatalf("Failed to delete book: %v", err)
+		if err := repo.DeleteBooks(ids); err != nil {
+			return i18n.Errorf("failed to delete book: %w", err)
 		}
 
-		fmt.Printf("Book with ID %d deleted successfully\n", id)
+		if single {
+			return printMessage(cmd, ids[0], "Book with ID %d deleted successfully", ids[0])
+		}
+		return printBulkMessage(cmd, ids, "%d book deleted (IDs %s)", "%d books deleted (IDs %s)")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(deleteCmd)
+	addBulkFlags(deleteCmd)
 }
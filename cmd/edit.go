@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/validation"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id|title>",
+	Short: "Edit a book in your text editor",
+	Long: `Edit a book in your text editor.
+
+The book is written to a temporary YAML file and opened in $VISUAL or
+$EDITOR (vi if neither is set). After the editor exits, the changed
+fields are checked and listed, and you are asked before they are stored.
+When a field is invalid, the editor opens again with the problem noted
+above the field. Fields removed from the file are left unchanged; saving
+an empty file cancels the edit.
+
+The book is given by ID, or by title or author as in show.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePositional(completeBooks),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !interactive() {
+			return usagef("edit needs a terminal; use update with field flags instead")
+		}
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewBookRepository(db)
+		id, err := findBookID(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+		book, err := repo.GetBookByID(id)
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+
+		doc := bookDocument(book)
+		for {
+			edited, err := editDocument(doc)
+			if err != nil {
+				return i18n.Errorf("failed to edit book: %w", err)
+			}
+			if len(bytes.TrimSpace(edited)) == 0 {
+				return printMessage(cmd, 0, "Edit cancelled")
+			}
+
+			changed, err := readBookDocument(edited, book)
+			if err == nil {
+				err = validation.Book(changed)
+			}
+			if err != nil {
+				doc = annotateDocument(book.ID, edited, err)
+				continue
+			}
+
+			changes := bookChanges(book, changed)
+			if len(changes) == 0 {
+				return printMessage(cmd, 0, "No changes made")
+			}
+			fmt.Fprintln(os.Stderr, strings.Join(changes, "\n"))
+			choice, err := choose(i18n.T("Store these changes?"), []string{i18n.T("Yes"), i18n.T("Edit again"), i18n.T("Discard them")})
+			if err != nil {
+				return i18n.Errorf("failed to edit book: %w", err)
+			}
+			switch choice {
+			case 1:
+				doc = edited
+				continue
+			case 2:
+				return printMessage(cmd, 0, "Edit cancelled")
+			}
+
+			if err := repo.UpdateBook(changed); err != nil {
+				var invalid validation.Errors
+				if !errors.As(err, &invalid) {
+					return i18n.Errorf("failed to update book: %w", err)
+				}
+				doc = annotateDocument(book.ID, edited, err)
+				continue
+			}
+			return printMessage(cmd, book.ID, "Book with ID %d updated successfully", book.ID)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+}
+
+// editField is a field of the edit document. Keys are the field names of
+// --where expressions, which validation errors use too.
+type editField struct {
+	key string
+	tag string // YAML type of the value, so that numbers are written unquoted
+	get func(b models.Book) string
+	set func(b *models.Book, value string) error
+}
+
+var editFields = []editField{
+	{"title", "!!str", func(b models.Book) string { return b.Title }, func(b *models.Book, v string) error { b.Title = v; return nil }},
+	{"author", "!!str", func(b models.Book) string { return b.Author }, func(b *models.Book, v string) error { b.Author = v; return nil }},
+	{"year", "!!int", func(b models.Book) string { return yearText(b.PublishedYear) }, setYear},
+	{"status", "!!str", func(b models.Book) string { return b.Status }, func(b *models.Book, v string) error { b.Status = strings.ToLower(v); return nil }},
+	{"finished", "!!str", func(b models.Book) string { return b.ReadDate }, func(b *models.Book, v string) error { b.ReadDate = v; return nil }},
+	{"publisher", "!!str", func(b models.Book) string { return b.Publisher }, func(b *models.Book, v string) error { b.Publisher = v; return nil }},
+	{"language", "!!str", func(b models.Book) string { return b.Language }, func(b *models.Book, v string) error { b.Language = canonicalLanguage(v); return nil }},
+	{"original_language", "!!str", func(b models.Book) string { return b.OriginalLanguage }, func(b *models.Book, v string) error { b.OriginalLanguage = canonicalLanguage(v); return nil }},
+	{"original_title", "!!str", func(b models.Book) string { return b.OriginalTitle }, func(b *models.Book, v string) error { b.OriginalTitle = v; return nil }},
+	{"isbn", "!!str", func(b models.Book) string { return b.ISBN }, func(b *models.Book, v string) error { b.ISBN = validation.NormalizeISBN(v); return nil }},
+	{"pages", "!!int", func(b models.Book) string { return pagesText(b.Pages) }, setPages},
+	{"location", "!!str", func(b models.Book) string { return b.Location }, func(b *models.Book, v string) error { b.Location = v; return nil }},
+	{"format", "!!str", func(b models.Book) string { return b.Format }, func(b *models.Book, v string) error { b.Format = strings.ToLower(v); return nil }},
+	{"purchased", "!!str", func(b models.Book) string { return b.PurchaseDate }, func(b *models.Book, v string) error { b.PurchaseDate = v; return nil }},
+	{"price", "!!float", func(b models.Book) string { return amountText(b.PurchasePrice) }, func(b *models.Book, v string) error { return setAmount(&b.PurchasePrice, v) }},
+	{"currency", "!!str", func(b models.Book) string { return b.Currency }, func(b *models.Book, v string) error { b.Currency = strings.ToUpper(v); return nil }},
+	{"value", "!!float", func(b models.Book) string { return amountText(b.EstimatedValue) }, func(b *models.Book, v string) error { return setAmount(&b.EstimatedValue, v) }},
+	{"notes", "!!str", func(b models.Book) string { return b.Notes }, func(b *models.Book, v string) error { b.Notes = v; return nil }},
+}
+
+func yearText(year int) string {
+	if year == 0 {
+		return ""
+	}
+	return strconv.Itoa(year)
+}
+
+func setYear(b *models.Book, value string) error {
+	if value == "" {
+		b.PublishedYear = 0
+		return nil
+	}
+	year, err := strconv.Atoi(value)
+	if err != nil {
+		return &validation.FieldError{Field: "year", Kind: validation.Malformed, Msg: i18n.Sprintf("%q is not a year", value)}
+	}
+	b.PublishedYear = year
+	return nil
+}
+
+func pagesText(pages int) string {
+	if pages == 0 {
+		return ""
+	}
+	return strconv.Itoa(pages)
+}
+
+func setPages(b *models.Book, value string) error {
+	if value == "" {
+		b.Pages = 0
+		return nil
+	}
+	pages, err := strconv.Atoi(value)
+	if err != nil {
+		return &validation.FieldError{Field: "pages", Kind: validation.Malformed, Msg: i18n.Sprintf("%q is not a number of pages", value)}
+	}
+	b.Pages = pages
+	return nil
+}
+
+func amountText(a models.Amount) string {
+	if a == 0 {
+		return ""
+	}
+	return a.String()
+}
+
+func setAmount(a *models.Amount, value string) error {
+	if value == "" {
+		*a = 0
+		return nil
+	}
+	amount, err := models.ParseAmount(value)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+const editHeader = `Book %d. Change the fields below, then save and quit the editor.
+Removed fields are left unchanged; an empty file cancels the edit.`
+
+// bookDocument writes book as the YAML document edit opens.
+func bookDocument(book models.Book) []byte {
+	fields := &yaml.Node{Kind: yaml.MappingNode, HeadComment: i18n.Sprintf(editHeader, book.ID)}
+	for _, f := range editFields {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: f.tag, Value: f.get(book)}
+		if value.Value == "" {
+			value.Tag = "!!str"
+		}
+		if strings.Contains(value.Value, "\n") {
+			value.Style = yaml.LiteralStyle
+		}
+		fields.Content = append(fields.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.key}, value)
+	}
+	return encodeDocument(fields)
+}
+
+func encodeDocument(node *yaml.Node) []byte {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	enc.Encode(node)
+	enc.Close()
+	return buf.Bytes()
+}
+
+// readBookDocument applies an edited document to a copy of book. Every
+// invalid value is reported, as validation.Errors for the fields or as a
+// plain error when the document cannot be read at all.
+func readBookDocument(doc []byte, book models.Book) (models.Book, error) {
+	fields, err := parseDocument(doc)
+	if err != nil {
+		return book, err
+	}
+
+	var errs validation.Errors
+	for i := 0; i < len(fields.Content); i += 2 {
+		key, value := fields.Content[i], fields.Content[i+1]
+		f, ok := findEditField(key.Value)
+		if !ok {
+			errs = append(errs, &validation.FieldError{Field: key.Value, Kind: validation.Unknown, Msg: i18n.T("is not a field of books")})
+			continue
+		}
+		if value.Kind != yaml.ScalarNode {
+			errs = append(errs, &validation.FieldError{Field: key.Value, Kind: validation.Malformed, Msg: i18n.T("must be a single value")})
+			continue
+		}
+		text := value.Value
+		if value.Tag == "!!null" {
+			text = ""
+		}
+		if f.key == "notes" {
+			text = strings.TrimRight(text, "\n")
+		} else {
+			text = strings.TrimSpace(text)
+		}
+		if err := f.set(&book, text); err != nil {
+			var fe *validation.FieldError
+			if !errors.As(err, &fe) {
+				fe = &validation.FieldError{Field: f.key, Kind: validation.Malformed, Msg: err.Error()}
+			}
+			errs = append(errs, fe)
+		}
+	}
+	if len(errs) > 0 {
+		return book, errs
+	}
+	return book, nil
+}
+
+// parseDocument returns the mapping of fields in an edited document.
+func parseDocument(doc []byte) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, i18n.Errorf("expected one field per line, such as title: The Hobbit")
+	}
+	return root.Content[0], nil
+}
+
+func findEditField(key string) (editField, bool) {
+	for _, f := range editFields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return editField{}, false
+}
+
+// annotateDocument notes the problems of err in an edited document for the
+// next round in the editor: field errors above their fields, other errors
+// at the top. Notes of the previous round are replaced.
+func annotateDocument(id int, doc []byte, err error) []byte {
+	var invalid validation.Errors
+	fields, parseErr := parseDocument(doc)
+	if parseErr != nil || !errors.As(err, &invalid) {
+		if parseErr != nil {
+			err = parseErr
+		}
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "# %s%s\n", errorNote(), strings.ReplaceAll(err.Error(), "\n", "\n# "))
+		for _, line := range strings.SplitAfter(string(doc), "\n") {
+			if !strings.HasPrefix(line, "# "+errorNote()) {
+				buf.WriteString(line)
+			}
+		}
+		return buf.Bytes()
+	}
+
+	for i := 0; i < len(fields.Content); i += 2 {
+		key := fields.Content[i]
+		key.HeadComment = ""
+		for _, fe := range invalid {
+			if fe.Field == key.Value {
+				key.HeadComment = errorNote() + fe.Error()
+			}
+		}
+	}
+	// Errors about fields removed from the document go to the top.
+	header := []string{i18n.Sprintf(editHeader, id)}
+	for _, fe := range invalid {
+		if !hasKey(fields, fe.Field) {
+			header = append(header, errorNote()+fe.Error())
+		}
+	}
+	fields.HeadComment = strings.Join(header, "\n\n")
+	return encodeDocument(fields)
+}
+
+// errorNote starts the notes of problems in an edited document.
+func errorNote() string {
+	return i18n.T("ERROR: ")
+}
+
+func hasKey(fields *yaml.Node, key string) bool {
+	for i := 0; i < len(fields.Content); i += 2 {
+		if fields.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// editDocument lets the user edit doc in their editor and returns the result.
+func editDocument(doc []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", "book-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(doc); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor setting may carry arguments, e.g. "code --wait".
+	args := append(strings.Fields(editor), f.Name())
+	editorCmd := exec.Command(args[0], args[1:]...)
+	editorCmd.Stdin, editorCmd.Stdout, editorCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return nil, i18n.Errorf("editor %s failed: %v", args[0], err)
+	}
+	return os.ReadFile(f.Name())
+}
+
+// bookChanges lists the fields that differ between two versions of a book,
+// e.g. "year: 1937 -> 1938".
+func bookChanges(old, new models.Book) []string {
+	var changes []string
+	for _, f := range editFields {
+		before, after := f.get(old), f.get(new)
+		if before == after {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", f.key, changeText(before), changeText(after)))
+	}
+	return changes
+}
+
+func changeText(value string) string {
+	if value == "" {
+		return i18n.T("(empty)")
+	}
+	return strconv.Quote(value)
+}
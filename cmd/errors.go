@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/output"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, one per kind of error.
+const (
+	exitFailure    = 1 // the database or the system failed
+	exitUsage      = 2 // the command line is wrong
+	exitNotFound   = 3 // a book or other record does not exist
+	exitValidation = 4 // the data given is invalid
+	exitConflict   = 5 // the change contradicts the stored data
+)
+
+const exitCodesHelp = `Exit codes: 0 success, 1 failure of the database or the system, 2 wrong
+usage, 3 not found, 4 invalid data, 5 conflict with stored data. With
+--output json, jsonl or yaml, errors are written to stderr in that format.`
+
+// usageError is an error in the command line rather than in the data.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+func usagef(format string, args ...any) error {
+	return usageError{i18n.Errorf(format, args...)}
+}
+
+// kindError gives an error found by a command itself one of the kinds of
+// repository errors.
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e kindError) Error() string   { return e.err.Error() }
+func (e kindError) Unwrap() []error { return []error{e.err, e.kind} }
+
+func notFoundf(format string, args ...any) error {
+	return kindError{i18n.Errorf(format, args...), repository.ErrNotFound}
+}
+
+func conflictf(format string, args ...any) error {
+	return kindError{i18n.Errorf(format, args...), repository.ErrConflict}
+}
+
+// running is set when a command starts running. Errors reported before,
+// by cobra checking flags and arguments, are usage errors.
+var running bool
+
+// trackRunning makes every command of the tree set running when it starts.
+func trackRunning(cmd *cobra.Command) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			running = true
+			return run(cmd, args)
+		}
+	}
+	for _, c := range cmd.Commands() {
+		trackRunning(c)
+	}
+}
+
+// execute runs the command line args, or os.Args when nil, and returns the
+// command that ran with its error, if any.
+func execute(args []string) (*cobra.Command, error) {
+	running = false
+	if args != nil {
+		rootCmd.SetArgs(args)
+	}
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil && !running && !errors.As(err, new(usageError)) {
+		err = usageError{err}
+	}
+	return cmd, err
+}
+
+// errorKind classifies err by name and exit code.
+func errorKind(err error) (string, int) {
+	switch {
+	case errors.As(err, new(usageError)):
+		return "usage", exitUsage
+	case errors.Is(err, repository.ErrNotFound):
+		return "not_found", exitNotFound
+	case errors.Is(err, repository.ErrValidation):
+		return "invalid", exitValidation
+	case errors.Is(err, repository.ErrConflict):
+		return "conflict", exitConflict
+	}
+	return "failure", exitFailure
+}
+
+// errorReport is how errors are written in structured output formats.
+type errorReport struct {
+	Error errorDetails `json:"error"`
+}
+
+type errorDetails struct {
+	Kind     string       `json:"kind"` // usage, not_found, invalid, conflict or failure
+	ExitCode int          `json:"exit_code"`
+	Message  string       `json:"message"`
+	Fields   []fieldError `json:"fields,omitempty"` // invalid fields of a book
+}
+
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// reportError writes err to stderr, in the structured format selected
+// with --output if any, and returns the exit code for it.
+func reportError(cmd *cobra.Command, err error) int {
+	kind, code := errorKind(err)
+	if r, rerr := renderer(cmd); rerr == nil && (r.Format == output.JSON || r.Format == output.JSONL || r.Format == output.YAML) {
+		details := errorDetails{Kind: kind, ExitCode: code, Message: err.Error()}
+		var invalid validation.Errors
+		if errors.As(err, &invalid) {
+			for _, fe := range invalid {
+				details.Fields = append(details.Fields, fieldError{fe.Field, fe.Msg})
+			}
+		}
+		if r.Render(os.Stderr, errorReport{details}) == nil {
+			return code
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, i18n.Sprintf("Error: %v", err))
+	if kind == "usage" && cmd != nil {
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Run '%s --help' for usage.", strings.TrimSpace(cmd.CommandPath())))
+	}
+	return code
+}
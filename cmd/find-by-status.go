@@ -1,42 +1,19 @@
 package cmd
 
 import (
-	"fmt"
-	"log"
-
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/filter"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
 	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
-	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
 	"github.com/spf13/cobra"
 )
 
 var findByIdStatusCmd = &cobra.Command{
 	Use:   "find-by-status",
 	Short: "Find books by status (read/unread)",
-	Run: func(cmd *cobra.Command, args []string) {
-		db, err := db.InitDB()
-		if err != nil {
-			log.Fatalf("Failed to connect to database: %v", err)
-		}
-		defer db.Close()
-
-		repo := repository.NewBookRepository(db)
-
+	Long:  `Find books by status. Shorthand for: book list --where 'status = <status>'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		status, _ := cmd.Flags().GetString("status")
-		books, err := repo.GetFilteredBooks(status)
-		if err != nil {
-			log.Fatalf("Failed to find books: %v", err)
-		}
-
-		if len(books) == 0 {
-			fmt.Println("No books found with status:", status)
-			return
-		}
-
-		fmt.Printf("Books with status '%s':\n", status)
-		for _, book := range books {
-			fmt.Printf("- ID: %d, Title: %s, Author: %s, Year: %d\n",
-				book.ID, book.Title, book.Author, book.PublishedYear)
-		}
+		return listBooks(cmd, repository.ListOptions{Where: "status = " + filter.Quote(status)})
 	},
 }
 
@@ -44,4 +21,5 @@ func init() {
 	rootCmd.AddCommand(findByIdStatusCmd)
 	findByIdStatusCmd.Flags().StringP("status", "s", "", "Filter by status (read/unread)")
 	findByIdStatusCmd.MarkFlagRequired("status")
+	findByIdStatusCmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(models.Statuses, cobra.ShellCompDirectiveNoFileComp))
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+)
+
+// findBookID resolves a book argument: a numeric ID, or a title or author typed
+// from memory, typos included. When several books match, the user picks one;
+// without a terminal to ask on, the candidates are returned in the error.
+func findBookID(repo *repository.BookRepository, arg string) (int, error) {
+	if id, err := strconv.Atoi(arg); err == nil {
+		return id, nil
+	}
+
+	results, err := repo.FuzzySearch(arg, 10)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case len(results) == 0:
+		return 0, notFoundf("no book matches %q", arg)
+	case len(results) == 1:
+		return results[0].ID, nil
+	case results[0].Rank == -1 && results[1].Rank != -1:
+		// Only one book matches every word exactly.
+		return results[0].ID, nil
+	}
+
+	options := make([]string, len(results))
+	for i, res := range results {
+		options[i] = i18n.Sprintf("%s by %s (%d), ID %d", res.Title, res.Author, res.PublishedYear, res.ID)
+	}
+	if !interactive() {
+		return 0, usagef("%q matches several books, give an ID instead:\n  %s", arg, strings.Join(options, "\n  "))
+	}
+	i, err := choose(i18n.Sprintf("Several books match %q:", arg), options)
+	if err != nil {
+		return 0, err
+	}
+	return results[i].ID, nil
+}
@@ -1,9 +1,10 @@
 package cmd
 
 import (
-	"database/sql"
-	"log"
+	"strings"
 
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
 	"github.com/belokosoff/golang-cobra-cli-crud/tui"
 	"github.com/spf13/cobra"
 )
@@ -11,17 +12,25 @@ import (
 var interactiveCommand = &cobra.Command{
 	Use:   "interactive",
 	Short: "Run TUI mode of application",
-	Run: func(cmd *cobra.Command, args []string) {
-		db, err := sql.Open("sqlite3", "./books.db")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("theme")
+		theme, err := tui.LookupTheme(name)
 		if err != nil {
-			log.Fatalf("Failed to open database: %v", err)
+			return usagef("invalid theme: %v", err)
+		}
+
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
 		}
 		defer db.Close()
 
-		tui.Start(db)
+		return tui.Start(db, theme)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(interactiveCommand)
+	interactiveCommand.Flags().String("theme", "dark", "Color theme: "+strings.Join(tui.ThemeNames(), ", "))
+	interactiveCommand.RegisterFlagCompletionFunc("theme", cobra.FixedCompletions(tui.ThemeNames(), cobra.ShellCompDirectiveNoFileComp))
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/config"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func init() {
+	rootCmd.PersistentFlags().String("lang", "", "Language of messages: "+strings.Join(i18n.Languages, ", ")+" (default from $LC_ALL, $LC_MESSAGES or $LANG)")
+	rootCmd.RegisterFlagCompletionFunc("lang", cobra.FixedCompletions(i18n.Languages, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// earlyFlag returns the value of a global flag on the command line args,
+// for the settings needed before cobra parses it.
+func earlyFlag(args []string, name string) string {
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.Usage = func() {}
+	flags.BoolP("help", "h", false, "")
+	value := flags.String(name, "", "")
+	flags.Parse(args)
+	return *value
+}
+
+// selectLanguage sets the language of messages from the lang setting in
+// args, the environment or file, falling back to the locale, and makes it
+// the default of the settings that depend on it.
+func selectLanguage(args []string, file *config.File) error {
+	lang := earlyFlag(args, "lang")
+	if lang == "" {
+		if s, err := findSetting("lang"); err == nil {
+			lang, _ = s.lookup(file)
+		}
+	}
+	if lang == "" {
+		lang = i18n.FromEnvironment()
+	}
+	if err := i18n.Set(lang); err != nil {
+		return usageError{err}
+	}
+
+	f := rootCmd.PersistentFlags().Lookup("date-format")
+	f.DefValue = i18n.DateFormat()
+	return f.Value.Set(f.DefValue)
+}
+
+// localize translates the help of the command tree, including the
+// commands and flags cobra adds, into the selected language.
+func localize(root *cobra.Command) {
+	root.InitDefaultHelpCmd()
+	root.InitDefaultCompletionCmd()
+	root.SetUsageTemplate(usageTemplate(root.UsageTemplate()))
+
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		c.InitDefaultHelpFlag()
+		c.Short = i18n.T(c.Short)
+		c.Long = i18n.Text(c.Long)
+		translate := func(f *pflag.Flag) {
+			if f.Name == "help" {
+				f.Usage = i18n.Sprintf("help for %s", c.Name())
+			} else {
+				f.Usage = i18n.T(f.Usage)
+			}
+		}
+		c.LocalNonPersistentFlags().VisitAll(translate)
+		c.PersistentFlags().VisitAll(translate)
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(root)
+}
+
+// usageTemplate translates the headings of cobra's usage template and the
+// defaults pflag notes after flags.
+func usageTemplate(tmpl string) string {
+	cobra.AddTemplateFunc("translateDefaults", func(s string) string {
+		return strings.ReplaceAll(s, " (default ", " ("+i18n.T("default")+" ")
+	})
+	pairs := []string{"FlagUsages |", "FlagUsages | translateDefaults |"}
+	for _, s := range []string{
+		"Usage:", "\nAliases:", "\nExamples:", "\nAvailable Commands:", "\nAdditional Commands:",
+		"\nFlags:", "\nGlobal Flags:", "\nAdditional help topics:",
+		`Use "{{.CommandPath}} [command] --help" for more information about a command.`,
+		"{{.CommandPath}} [command]",
+	} {
+		text := strings.TrimPrefix(s, "\n")
+		pairs = append(pairs, s, strings.Replace(s, text, i18n.T(text), 1))
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"strings"
 
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/filter"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
 	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
 	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
 	"github.com/spf13/cobra"
@@ -12,32 +16,116 @@ import (
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Output the list of book",
-	Run: func(cmd *cobra.Command, args []string) {
-		db, err := db.InitDB()
-		if err != nil {
-			log.Fatalf("Failed to connect to database: %v", err)
-		}
-		defer db.Close()
+	Long: `Output the list of books, optionally filtered, sorted and paginated.
 
-		repo := repository.NewBookRepository(db)
+Filter expressions compare fields with =, !=, <, <=, >, >=, ~ (contains,
+case-insensitive) and !~, combined with and, or, not and parentheses:
 
-		books, err := repo.GetAllBooks()
-		if err != nil {
-			log.Fatalf("Failed to find books: %v", err)
-		}
+  book list --where 'author ~ "tolkien" and year >= 1950 and status != read'
 
-		if len(books) == 0 {
-			fmt.Println("No books found")
-			return
-		}
+Sorting takes a comma separated list of fields, "-" sorts descending:
+
+  book list --sort author,-year --limit 20 --page 3
+
+Every paginated listing prints a cursor for the next page; --after <cursor>
+continues from there and stays fast however deep the page is.
+
+Fields: ` + strings.Join(filter.Fields(), ", "),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := repository.ListOptions{}
+		opts.Where, _ = cmd.Flags().GetString("where")
+		opts.Sort, _ = cmd.Flags().GetString("sort")
+		opts.Limit, _ = cmd.Flags().GetInt("limit")
+		opts.Offset, _ = cmd.Flags().GetInt("offset")
+		opts.After, _ = cmd.Flags().GetString("after")
 
-		for _, book := range books {
-			fmt.Printf("- ID: %d, Title: %s, Author: %s, Year: %d, Status: %s\n",
-				book.ID, book.Title, book.Author, book.PublishedYear, book.Status)
+		if cmd.Flags().Changed("page") {
+			page, _ := cmd.Flags().GetInt("page")
+			if page < 1 {
+				return usagef("invalid page %d: pages start at 1", page)
+			}
+			if opts.Limit <= 0 {
+				return usagef("--page requires --limit")
+			}
+			opts.Offset = (page - 1) * opts.Limit
 		}
+		if opts.Offset < 0 {
+			return usagef("invalid offset %d", opts.Offset)
+		}
+
+		return listBooks(cmd, opts)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringP("where", "w", "", "Filter expression, e.g. 'status = unread and year < 1900'")
+	listCmd.Flags().String("sort", "", "Sort fields, e.g. title,-year (default: id)")
+	listCmd.Flags().IntP("limit", "n", 0, "Maximum number of books to show (0: all)")
+	listCmd.Flags().Int("offset", 0, "Number of books to skip")
+	listCmd.Flags().IntP("page", "p", 1, "Page number, counted in --limit sized pages")
+	listCmd.Flags().String("after", "", "Continue after the cursor printed with the previous page")
+	listCmd.MarkFlagsMutuallyExclusive("offset", "page", "after")
+	listCmd.RegisterFlagCompletionFunc("sort", completeSort)
+}
+
+// listBooks prints one page of books.
+func listBooks(cmd *cobra.Command, opts repository.ListOptions) error {
+	db, err := db.InitDB()
+	if err != nil {
+		return i18n.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewBookRepository(db)
+
+	page, err := repo.ListBooks(opts)
+	if err != nil {
+		return i18n.Errorf("failed to find books: %w", err)
+	}
+
+	result := bookPage{Books: page.Books, Total: page.Total, Next: page.Next}
+	if result.Books == nil {
+		result.Books = []models.Book{}
+	}
+	if opts.Limit > 0 {
+		result.Page = page.Offset/opts.Limit + 1
+		result.Pages = (page.Total + opts.Limit - 1) / opts.Limit
+		result.offset = page.Offset
+	}
+	return render(cmd, result)
+}
+
+type bookPage struct {
+	Books  []models.Book `json:"books"`
+	Total  int           `json:"total"`
+	Page   int           `json:"page,omitempty"`
+	Pages  int           `json:"pages,omitempty"`
+	Next   string        `json:"next,omitempty"` // cursor for --after
+	offset int
+}
+
+func (p bookPage) Items() any {
+	return p.Books
+}
+
+func (p bookPage) WriteTable(w io.Writer) error {
+	if len(p.Books) == 0 {
+		_, err := fmt.Fprintln(w, i18n.T("No books found"))
+		return err
+	}
+
+	for _, book := range p.Books {
+		fmt.Fprintln(w, i18n.Sprintf("- ID: %d, Title: %s, Author: %s, Year: %d, Status: %s",
+			book.ID, book.Title, book.Author, book.PublishedYear, i18n.T(book.Status)))
+	}
+
+	if p.Page > 0 {
+		fmt.Fprintln(w, "\n"+i18n.Sprintf("Page %d of %d (books %d-%d of %d)", p.Page, p.Pages,
+			p.offset+1, p.offset+len(p.Books), p.Total))
+		if p.Next != "" {
+			fmt.Fprintln(w, i18n.Sprintf("Next page: --after %s", p.Next))
+		}
+	}
+	return nil
 }
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "Output format: table, json, jsonl, csv, tsv, yaml")
+	rootCmd.PersistentFlags().String("template", "", "Go text/template applied to every record, e.g. '{{.Title}} ({{.PublishedYear}})'")
+	rootCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		var formats []cobra.Completion
+		for _, f := range output.Formats {
+			formats = append(formats, string(f))
+		}
+		return formats, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.PersistentFlags().String("date-format", "YYYY-MM-DD", "Format of dates in table output, e.g. DD.MM.YYYY or D MMM YYYY")
+}
+
+// dateLayout is the time layout of dates in table output, see --date-format.
+// Other formats keep dates in ISO 8601 form.
+var dateLayout = time.DateOnly
+
+// applyDateFormat sets dateLayout from the --date-format flag.
+func applyDateFormat(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString("date-format")
+	if !strings.ContainsAny(format, "YMD") {
+		return usagef("invalid date format %q: expected e.g. DD.MM.YYYY", format)
+	}
+	dateLayout = strings.NewReplacer(
+		"YYYY", "2006", "YY", "06",
+		"MMMM", "January", "MMM", "Jan", "MM", "01", "M", "1",
+		"DD", "02", "D", "2",
+	).Replace(format)
+	return nil
+}
+
+// formatDate writes an ISO 8601 date in the format of table output. Empty
+// and malformed dates are returned unchanged.
+func formatDate(date string) string {
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return date
+	}
+	return i18n.Date(t, dateLayout)
+}
+
+// renderer returns the output renderer selected by the global flags.
+func renderer(cmd *cobra.Command) (*output.Renderer, error) {
+	name, _ := cmd.Flags().GetString("output")
+	tmpl, _ := cmd.Flags().GetString("template")
+
+	format, err := output.ParseFormat(name)
+	if err != nil {
+		return nil, usagef("invalid output format: %v", err)
+	}
+	r, err := output.New(format, tmpl)
+	if err != nil {
+		return nil, usagef("invalid output format: %v", err)
+	}
+	return r, nil
+}
+
+// render writes v to stdout in the selected output format.
+func render(cmd *cobra.Command, v any) error {
+	r, err := renderer(cmd)
+	if err != nil {
+		return err
+	}
+	if err := r.Render(os.Stdout, v); err != nil {
+		return i18n.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// message is the result of commands that change data.
+type message struct {
+	ID      int    `json:"id,omitempty"`
+	IDs     []int  `json:"ids,omitempty"` // books changed by a bulk command
+	Message string `json:"message"`
+}
+
+func (m message) WriteTable(w io.Writer) error {
+	_, err := fmt.Fprintln(w, m.Message)
+	return err
+}
+
+// printMessage reports the outcome of a command that changed the book
+// with the given ID (0 if not about a single book).
+func printMessage(cmd *cobra.Command, id int, format string, args ...any) error {
+	return render(cmd, message{ID: id, Message: i18n.Sprintf(format, args...)})
+}
+
+// writeHeader writes the column headings of a table, translated and
+// underlined.
+func writeHeader(w io.Writer, columns ...string) {
+	var headings, lines strings.Builder
+	for _, c := range columns {
+		c = i18n.T(c)
+		headings.WriteString(c + "\t")
+		lines.WriteString(strings.Repeat("-", utf8.RuneCountInString(c)) + "\t")
+	}
+	fmt.Fprintln(w, headings.String())
+	fmt.Fprintln(w, lines.String())
+}
+
+// formatAmount writes an amount of money in the notation of table output,
+// e.g. 1 234,50 in Russian.
+func formatAmount(a models.Amount) string {
+	return i18n.Number(float64(a)/100, 2)
+}
+
+// writeField writes a row of a table of fields, the label translated.
+func writeField(w io.Writer, label string, value any) {
+	fmt.Fprintf(w, "%s\t%v\n", i18n.T(label), value)
+}
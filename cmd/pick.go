@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/filter"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+// preferFactor multiplies the weight of a book for every --prefer
+// expression it matches.
+const preferFactor = 3
+
+// recentDivisor divides the weight of a book whose author was read within
+// --recent-days, so that authors take turns.
+const recentDivisor = 4
+
+var pickCmd = &cobra.Command{
+	Use:   "pick",
+	Short: "Pick an unread book to read next",
+	Long: `Pick an unread book to read next, at random with weights.
+
+Every unread book starts with weight 1. A book on the shelf for a while
+gains 1 for every year since its purchase date, so long neglected books
+come up more often. A book matching a --prefer filter expression weighs
+` + fmt.Sprint(preferFactor) + ` times as much, for each expression it matches. A book by an author
+you finished another book of within --recent-days weighs 1/` + fmt.Sprint(recentDivisor) + ` as much.
+
+--where leaves out the books that do not match a filter expression, and
+--min-pages and --max-pages those of other lengths; books without a page
+count are kept:
+
+  book pick --prefer 'language = ru' --prefer 'format = ebook' --where 'year >= 1900'
+  book pick --max-pages 300 --recent-days 365
+
+The result lists the candidates with the highest weights, their chance
+of being picked and why they weigh what they do. Picks are random; the
+seed printed with one repeats it when given with --seed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		where, _ := cmd.Flags().GetString("where")
+		prefer, _ := cmd.Flags().GetStringArray("prefer")
+		show, _ := cmd.Flags().GetInt("candidates")
+		minPages, _ := cmd.Flags().GetInt("min-pages")
+		maxPages, _ := cmd.Flags().GetInt("max-pages")
+		recentDays, _ := cmd.Flags().GetInt("recent-days")
+		seed, _ := cmd.Flags().GetUint64("seed")
+		if !cmd.Flags().Changed("seed") {
+			seed = uint64(rand.Uint32())
+		}
+		switch {
+		case minPages < 0:
+			return usagef("invalid --min-pages %d", minPages)
+		case maxPages < 0:
+			return usagef("invalid --max-pages %d", maxPages)
+		case recentDays < 0:
+			return usagef("invalid --recent-days %d", recentDays)
+		}
+
+		conn, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer conn.Close()
+
+		repo := repository.NewBookRepository(conn)
+		opts := repository.ListOptions{Where: where, And: []string{"status = unread"}}
+		if minPages > 0 {
+			opts.And = append(opts.And, fmt.Sprintf("pages = 0 or pages >= %d", minPages))
+		}
+		if maxPages > 0 {
+			opts.And = append(opts.And, fmt.Sprintf("pages = 0 or pages <= %d", maxPages))
+		}
+		unread, err := repo.ListBooks(opts)
+		if err != nil {
+			return i18n.Errorf("failed to find books: %w", err)
+		}
+		books := unread.Books
+		if len(books) == 0 {
+			return notFoundf("no unread books to pick from")
+		}
+
+		preferred := make([]map[int]bool, len(prefer))
+		for i, expr := range prefer {
+			matches, err := repo.FindBooks(expr)
+			if err != nil {
+				return i18n.Errorf("failed to apply --prefer %q: %w", expr, err)
+			}
+			preferred[i] = map[int]bool{}
+			for _, b := range matches {
+				preferred[i][b.ID] = true
+			}
+		}
+
+		// Weights change once a day, so a seed repeats a pick all day.
+		today, _ := time.Parse(time.DateOnly, time.Now().Format(time.DateOnly))
+
+		// The last read date of every author read within --recent-days.
+		recent := map[int]string{}
+		if recentDays > 0 {
+			since := today.AddDate(0, 0, -recentDays).Format(time.DateOnly)
+			read, err := repo.ListBooks(repository.ListOptions{
+				Where: "status = read",
+				And:   []string{"finished >= " + filter.Quote(since)},
+			})
+			if err != nil {
+				return i18n.Errorf("failed to find books: %w", err)
+			}
+			for _, b := range read.Books {
+				if b.AuthorID != 0 && b.ReadDate > recent[b.AuthorID] {
+					recent[b.AuthorID] = b.ReadDate
+				}
+			}
+		}
+
+		candidates := make([]pickCandidate, len(books))
+		var total float64
+		for i, book := range books {
+			candidates[i] = scoreBook(book, prefer, preferred, recent, today)
+			total += candidates[i].Weight
+		}
+		for i := range candidates {
+			candidates[i].Chance = math.Round(candidates[i].Weight/total*1000) / 10
+		}
+
+		// Candidates are ordered by ID before drawing, so a seed picks the
+		// same book whatever order the database returns them in.
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+		r := rand.New(rand.NewPCG(seed, 0))
+		draw := r.Float64() * total
+		picked := len(candidates) - 1
+		for i, c := range candidates {
+			if draw < c.Weight {
+				picked = i
+				break
+			}
+			draw -= c.Weight
+		}
+		candidates[picked].Picked = true
+		pick := candidates[picked]
+
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Weight > candidates[j].Weight })
+		if show > 0 && show < len(candidates) {
+			candidates = candidates[:show]
+		}
+		return render(cmd, pickResult{Pick: pick, Candidates: candidates, Considered: len(books), Seed: seed})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pickCmd)
+	pickCmd.Flags().StringP("where", "w", "", "Only pick books matching a filter expression, e.g. 'year >= 1900'")
+	pickCmd.Flags().StringArrayP("prefer", "p", nil, "Weigh books matching a filter expression higher (repeatable)")
+	pickCmd.Flags().Int("min-pages", 0, "Only pick books of at least this many pages")
+	pickCmd.Flags().Int("max-pages", 0, "Only pick books of at most this many pages")
+	pickCmd.Flags().Int("recent-days", 180, "Days within which a finished author's other books weigh less (0: never)")
+	pickCmd.Flags().IntP("candidates", "n", 5, "Number of candidates to explain (0: all)")
+	pickCmd.Flags().Uint64("seed", 0, "Seed of the random pick, to repeat an earlier one")
+}
+
+// scoreBook weighs an unread book for pick. preferred holds, for every
+// --prefer expression, the IDs of the books matching it, and recent the
+// last read date of the authors read recently, by author ID.
+func scoreBook(book models.Book, prefer []string, preferred []map[int]bool, recent map[int]string, now time.Time) pickCandidate {
+	c := pickCandidate{ID: book.ID, Title: book.Title, Author: book.Author, Weight: 1, Reasons: []string{}}
+	if bought, err := time.Parse(time.DateOnly, book.PurchaseDate); err == nil && bought.Before(now) {
+		years := math.Round(now.Sub(bought).Hours()/24/365.25*10) / 10
+		c.Weight += years
+		c.Reasons = append(c.Reasons, i18n.Sprintf("on the shelf %s years (+%s)", i18n.Number(years, 1), i18n.Number(years, 1)))
+	}
+	for i, expr := range prefer {
+		if preferred[i][book.ID] {
+			c.Weight *= preferFactor
+			c.Reasons = append(c.Reasons, i18n.Sprintf("matches %q (×%d)", expr, preferFactor))
+		}
+	}
+	if date, ok := recent[book.AuthorID]; ok {
+		c.Weight /= recentDivisor
+		c.Reasons = append(c.Reasons, i18n.Sprintf("author read on %s (÷%d)", formatDate(date), recentDivisor))
+	}
+	return c
+}
+
+type pickCandidate struct {
+	ID      int      `json:"id"`
+	Title   string   `json:"title"`
+	Author  string   `json:"author"`
+	Weight  float64  `json:"weight"`
+	Chance  float64  `json:"chance"` // percent
+	Reasons []string `json:"reasons"`
+	Picked  bool     `json:"picked"`
+}
+
+type pickResult struct {
+	Pick       pickCandidate   `json:"pick"`
+	Candidates []pickCandidate `json:"candidates"` // highest weights first
+	Considered int             `json:"considered"` // number of unread books weighed
+	Seed       uint64          `json:"seed"`
+}
+
+// Items makes csv and similar formats list the candidates.
+func (r pickResult) Items() any {
+	return r.Candidates
+}
+
+func (r pickResult) WriteTable(out io.Writer) error {
+	fmt.Fprintln(out, i18n.Sprintf("Read next: %s by %s (ID %d)", r.Pick.Title, r.Pick.Author, r.Pick.ID))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w)
+	writeHeader(w, "ID", "TITLE", "AUTHOR", "WEIGHT", "CHANCE", "WHY")
+	for _, c := range r.Candidates {
+		why := strings.Join(c.Reasons, "; ")
+		if why == "" {
+			why = i18n.T("unread")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s%%\t%s\t\n", c.ID, c.Title, c.Author, i18n.Number(c.Weight, 2), i18n.Number(c.Chance, 0), why)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\n"+i18n.N(r.Considered, "%d unread book weighed; repeat this pick with --seed %d",
+		"%d unread books weighed; repeat this pick with --seed %d", r.Considered, r.Seed))
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+// isTerminal reports whether f is connected to a terminal rather than a
+// pipe or a file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// interactive reports whether the user can be asked questions. Commands
+// in a batch never ask.
+func interactive() bool {
+	return !batchMode && isTerminal(os.Stdin) && isTerminal(os.Stderr)
+}
+
+// stdin is shared by all prompts, so that input buffered by one is not lost
+// to the next.
+var stdin = bufio.NewReader(os.Stdin)
+
+// ask prompts for a line of input; an empty answer stands for def. check,
+// if not nil, validates the answer and returns the value to use; the
+// question is repeated until it passes.
+func ask(question, def string, check func(string) (string, error)) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(os.Stderr, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: ", question)
+		}
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return "", i18n.Errorf("no answer given")
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = def
+		}
+		if check == nil {
+			return answer, nil
+		}
+		value, checkErr := check(answer)
+		if checkErr == nil {
+			return value, nil
+		}
+		fmt.Fprintf(os.Stderr, "  %v\n", checkErr)
+		if err != nil {
+			return "", checkErr
+		}
+	}
+}
+
+// choose asks the user to pick one of options by number and returns its
+// index. Prompts go to stderr so they do not mix with the command output.
+func choose(question string, options []string) (int, error) {
+	fmt.Fprintln(os.Stderr, question)
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, option)
+	}
+
+	for {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("Choose 1-%d: ", len(options)))
+		line, err := stdin.ReadString('\n')
+		if n, convErr := strconv.Atoi(strings.TrimSpace(line)); convErr == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		if err != nil {
+			return 0, i18n.Errorf("no choice made")
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var quoteCmd = &cobra.Command{
+	Use:   "quote",
+	Short: "Keep quotes from books",
+}
+
+var quoteAddCmd = &cobra.Command{
+	Use:               "add <book> <text>...",
+	Short:             "Add a quote from a book",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completePositional(completeBooks),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewBookRepository(db)
+		bookID, err := findBookID(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+		quote := models.Quote{BookID: bookID, Text: strings.Join(args[1:], " ")}
+		quote.Page, _ = cmd.Flags().GetInt("page")
+
+		id, err := repo.AddQuote(quote)
+		if err != nil {
+			return i18n.Errorf("failed to add quote: %w", err)
+		}
+		return printMessage(cmd, id, "Quote with ID %d added successfully", id)
+	},
+}
+
+var quoteListCmd = &cobra.Command{
+	Use:               "list <book>",
+	ValidArgsFunction: completePositional(completeBooks),
+	Short:             "List the quotes from a book",
+	Args:              cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewBookRepository(db)
+		bookID, err := findBookID(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+
+		if _, err := repo.GetBookByID(bookID); err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+		quotes, err := repo.GetQuotes(bookID)
+		if err != nil {
+			return i18n.Errorf("failed to find quotes: %w", err)
+		}
+
+		if quotes == nil {
+			quotes = []models.Quote{}
+		}
+		return render(cmd, quoteList(quotes))
+	},
+}
+
+var quoteDeleteCmd = &cobra.Command{
+	Use:   "delete <quote-id>",
+	Short: "Delete a quote",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return usagef("invalid quote ID %q", args[0])
+		}
+
+		repo := repository.NewBookRepository(db)
+		if err := repo.DeleteQuote(id); err != nil {
+			return i18n.Errorf("failed to delete quote: %w", err)
+		}
+		return printMessage(cmd, id, "Quote with ID %d deleted successfully", id)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quoteCmd)
+	quoteCmd.AddCommand(quoteAddCmd, quoteListCmd, quoteDeleteCmd)
+	quoteAddCmd.Flags().IntP("page", "p", 0, "Page the quote is on")
+}
+
+type quoteList []models.Quote
+
+func (l quoteList) WriteTable(w io.Writer) error {
+	if len(l) == 0 {
+		_, err := fmt.Fprintln(w, i18n.T("No quotes found"))
+		return err
+	}
+
+	for _, q := range l {
+		fmt.Fprintf(w, "- ID: %d%s\n  %s\n", q.ID, quotePage(q), q.Text)
+	}
+	return nil
+}
+
+func quotePage(q models.Quote) string {
+	if q.Page == 0 {
+		return ""
+	}
+	return i18n.Sprintf(", page %d", q.Page)
+}
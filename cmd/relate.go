@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var relateCmd = &cobra.Command{
+	Use:   "relate <id> <type> <id>",
+	Short: "Relate two books (sequel-of, translation-of, contains, companion-to)",
+	Long: `Relate two books.
+
+  book relate 12 contains 7        omnibus 12 contains novel 7
+  book relate 8 translation-of 3   book 8 is a translation of book 3
+  book relate 5 sequel-of 4        book 5 continues book 4
+  book relate 9 companion-to 4     books 9 and 4 belong together
+
+Relations that would form a cycle are rejected.`,
+	Args:              cobra.ExactArgs(3),
+	ValidArgsFunction: completePositional(completeBooks, completeRelationTypes, completeBooks),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewBookRepository(db)
+		bookID, err := findBookID(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+		typ, err := models.ParseRelationType(args[1])
+		if err != nil {
+			return usagef("invalid relation: %v", err)
+		}
+		relatedID, err := findBookID(repo, args[2])
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+
+		remove, _ := cmd.Flags().GetBool("remove")
+		if remove {
+			if err := repo.RemoveRelation(bookID, typ, relatedID); err != nil {
+				return i18n.Errorf("failed to remove relation: %w", err)
+			}
+			return printMessage(cmd, bookID, "Relation %d %s %d removed successfully", bookID, typ, relatedID)
+		}
+
+		if err := repo.AddRelation(bookID, typ, relatedID); err != nil {
+			return i18n.Errorf("failed to relate books: %w", err)
+		}
+		return printMessage(cmd, bookID, "Relation %d %s %d added successfully", bookID, typ, relatedID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(relateCmd)
+	relateCmd.Flags().Bool("remove", false, "Remove the relation instead of adding it")
+}
+
+func completeRelationTypes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var completions []cobra.Completion
+	for _, t := range models.RelationTypes {
+		completions = append(completions, string(t))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,20 +1,38 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/config"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "book",
 	Short: "A tool to storage book library",
+	Long: `A tool to storage book library.
+
+` + exitCodesHelp,
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	args := os.Args[1:]
+	file, err := loadConfig(earlyFlag(args, "config"))
+	if err != nil {
+		file = &config.File{}
+	}
+	if langErr := selectLanguage(args, file); langErr != nil && err == nil {
+		err = langErr
+	}
+	if err != nil {
+		os.Exit(reportError(rootCmd, err))
+	}
+	registerAliases(file)
+	localize(rootCmd)
+	trackRunning(rootCmd)
+	if cmd, err := execute(nil); err != nil {
+		os.Exit(reportError(cmd, err))
 	}
 }
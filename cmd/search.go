@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search titles, authors, notes and quotes",
+	Long: `Search titles, original titles, authors (with their aliases), notes and
+quotes. Results are ranked by relevance (BM25), best first.
+
+  book search tolkien ring          books matching both words
+  book search 'tolk*'               words starting with "tolk"
+  book search '"war and peace"'     the exact phrase
+  book search 'hobbit OR silmarillion'
+  book search 'ring NOT author:tolkien'
+
+Columns usable with column:word are title, original_title, author, notes
+and quotes. In structured output the matched words of a snippet are
+enclosed in [brackets].
+
+With --fuzzy, titles and authors are matched despite typos instead:
+"dostoyevsky" finds "Dostoevsky" and "tolkein" finds "Tolkien". Query
+syntax does not apply then; every word is compared on its own.
+
+Searching needs a build with FTS5, which make build and make install
+produce (go build -tags sqlite_fts5). Other builds only search with --fuzzy.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer conn.Close()
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		fuzzy, _ := cmd.Flags().GetBool("fuzzy")
+
+		if !fuzzy && !db.HasFullTextSearch(conn) {
+			return i18n.Errorf("this build has no FTS5 support; install book with make install, or search with --fuzzy")
+		}
+
+		repo := repository.NewBookRepository(conn)
+		search := repo.Search
+		if fuzzy {
+			search = repo.FuzzySearch
+		}
+		results, err := search(strings.Join(args, " "), limit)
+		if err != nil {
+			return i18n.Errorf("failed to search books: %w", err)
+		}
+
+		found := make([]searchResult, len(results))
+		for i, res := range results {
+			found[i] = searchResult{SearchResult: res, Snippet: res.Highlight(brackets)}
+		}
+		return render(cmd, searchResults(found))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntP("limit", "n", 20, "Maximum number of results")
+	searchCmd.Flags().BoolP("fuzzy", "f", false, "Tolerate typos in titles and authors")
+}
+
+func brackets(hit string) string {
+	return "[" + hit + "]"
+}
+
+type searchResult struct {
+	repository.SearchResult
+	Snippet string `json:"snippet"` // matched words in [brackets]
+}
+
+type searchResults []searchResult
+
+func (r searchResults) WriteTable(w io.Writer) error {
+	if len(r) == 0 {
+		_, err := fmt.Fprintln(w, i18n.T("No books found"))
+		return err
+	}
+
+	style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	hit := func(s string) string { return style.Render(s) }
+	for _, res := range r {
+		book := res.Book
+		fmt.Fprintln(w, i18n.Sprintf("- ID: %d, Title: %s, Author: %s, Year: %d, Status: %s",
+			book.ID, book.Title, book.Author, book.PublishedYear, i18n.T(book.Status)))
+		if res.Snippet != "" {
+			fmt.Fprintf(w, "    %s\n", res.SearchResult.Highlight(hit))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <id|title>",
+	Short: "Show all details of a book",
+	Long: `Show all details of a book.
+
+The book is given by ID, or by title or author as remembered: "book show
+hobit" finds "The Hobbit". When several books match, you are asked which
+one you mean.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePositional(completeBooks),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewBookRepository(db)
+		id, err := findBookID(repo, args[0])
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+
+		book, err := repo.GetBookByID(id)
+		if err != nil {
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+
+		details := bookDetails{Book: book, AuthorName: book.Author}
+		if book.AuthorID != 0 {
+			author, err := repository.NewAuthorRepository(db).GetAuthorByID(book.AuthorID)
+			if err != nil {
+				return i18n.Errorf("failed to find author: %w", err)
+			}
+			details.AuthorName = author.Name
+		}
+
+		details.Relations, err = repo.GetRelations(book.ID)
+		if err != nil {
+			return i18n.Errorf("failed to find related books: %w", err)
+		}
+		details.Quotes, err = repo.GetQuotes(book.ID)
+		if err != nil {
+			return i18n.Errorf("failed to find quotes: %w", err)
+		}
+		if details.Quotes == nil {
+			details.Quotes = []models.Quote{}
+		}
+
+		return render(cmd, details)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+}
+
+// bookDetails is everything show knows about a book.
+type bookDetails struct {
+	models.Book
+	AuthorName string            `json:"author_name"` // canonical name of the author record
+	Relations  []models.Relation `json:"relations"`
+	Quotes     []models.Quote    `json:"quotes"`
+}
+
+func (d bookDetails) WriteTable(out io.Writer) error {
+	book := d.Book
+	author := book.Author
+	if d.AuthorName != book.Author {
+		author = fmt.Sprintf("%s (%s)", book.Author, d.AuthorName)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	writeField(w, "ID", book.ID)
+	writeField(w, "Title", book.Title)
+	writeField(w, "Author", author)
+	writeField(w, "Year", book.PublishedYear)
+	writeField(w, "Status", i18n.T(book.Status))
+	writeField(w, "Finished", formatDate(book.ReadDate))
+	writeField(w, "Publisher", book.Publisher)
+	writeField(w, "Language", book.Language)
+	writeField(w, "Original language", book.OriginalLanguage)
+	writeField(w, "Original title", book.OriginalTitle)
+	writeField(w, "ISBN", book.ISBN)
+	writeField(w, "Pages", pagesText(book.Pages))
+	writeField(w, "Location", book.Location)
+	writeField(w, "Format", book.Format)
+	writeField(w, "Purchased", formatDate(book.PurchaseDate))
+	if book.Currency != "" {
+		writeField(w, "Price", formatAmount(book.PurchasePrice)+" "+book.Currency)
+		if book.EstimatedValue != 0 {
+			writeField(w, "Estimated value", formatAmount(book.EstimatedValue)+" "+book.Currency)
+		}
+	}
+	if book.Cover != "" {
+		writeField(w, "Cover", filepath.Join(db.CoversDir(), book.Cover))
+	}
+	for i, rel := range d.Relations {
+		label := ""
+		if i == 0 {
+			label = "Related"
+		}
+		writeField(w, label, i18n.Sprintf("%s: %s (ID %d)", i18n.T(rel.Label()), rel.RelatedTitle, rel.RelatedID))
+	}
+	if book.Notes != "" {
+		writeField(w, "Notes", book.Notes)
+	}
+	for i, q := range d.Quotes {
+		label := ""
+		if i == 0 {
+			label = "Quotes"
+		}
+		writeField(w, label, i18n.Sprintf("“%s”", q.Text)+quotePage(q))
+	}
+	return w.Flush()
+}
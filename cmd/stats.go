@@ -3,41 +3,85 @@ package cmd
 import (
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"text/tabwriter"
 
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
 	"github.com/spf13/cobra"
 )
 
 var statsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show book statistics",
-	Run: func(cmd *cobra.Command, args []string) {
-		db, err := sql.Open("sqlite3", "./books.db")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
 		if err != nil {
-			log.Fatalf("Failed to open database: %v", err)
+			return i18n.Errorf("failed to connect to database: %w", err)
 		}
 		defer db.Close()
 
 		byYear, _ := cmd.Flags().GetBool("by-year")
 		byAuthor, _ := cmd.Flags().GetBool("by-author")
 		byStatus, _ := cmd.Flags().GetBool("by-status")
+		byLanguage, _ := cmd.Flags().GetBool("by-language")
+		translated, _ := cmd.Flags().GetBool("translated")
 
-		if !byYear && !byAuthor && !byStatus {
-			showBasicStats(db)
-			return
+		var report statsReport
+		if !byYear && !byAuthor && !byStatus && !byLanguage && !translated {
+			if report.Summary, err = basicStats(db); err != nil {
+				return i18n.Errorf("failed to count books: %w", err)
+			}
 		}
-
 		if byYear {
-			showYearStats(db)
+			report.ByYear, err = countBy(db, `
+				SELECT published_year, COUNT(*) as count 
+				FROM books 
+				GROUP BY published_year 
+				ORDER BY published_year DESC`)
+			if err != nil {
+				return i18n.Errorf("failed to count books: %w", err)
+			}
 		}
 		if byAuthor {
-			showAuthorStats(db)
+			// Books are counted per author record, so aliases and spelling
+			// variants of one author are added up.
+			report.ByAuthor, err = countBy(db, `
+				SELECT COALESCE(a.name, b.author) as name, COUNT(*) as count 
+				FROM books b 
+				LEFT JOIN authors a ON a.id = b.author_id 
+				GROUP BY COALESCE(b.author_id, b.author) 
+				ORDER BY count DESC`)
+			if err != nil {
+				return i18n.Errorf("failed to count books: %w", err)
+			}
 		}
 		if byStatus {
-			showStatusStats(db)
+			report.ByStatus, err = countBy(db, `
+				SELECT status, COUNT(*) as count 
+				FROM books 
+				GROUP BY status`)
+			if err != nil {
+				return i18n.Errorf("failed to count books: %w", err)
+			}
+		}
+		if byLanguage {
+			report.ByLanguage, err = countBy(db, `
+				SELECT language, COUNT(*) as count 
+				FROM books 
+				GROUP BY language 
+				ORDER BY count DESC`)
+			if err != nil {
+				return i18n.Errorf("failed to count books: %w", err)
+			}
 		}
+		if translated {
+			if report.Translations, err = translationStats(db); err != nil {
+				return i18n.Errorf("failed to count translations: %w", err)
+			}
+		}
+
+		return render(cmd, report)
 	},
 }
 
@@ -47,104 +91,217 @@ func init() {
 	statsCmd.Flags().BoolP("by-year", "y", false, "Show statistics by publication year")
 	statsCmd.Flags().BoolP("by-author", "a", false, "Show statistics by author")
 	statsCmd.Flags().BoolP("by-status", "s", false, "Show read/unread statistics")
+	statsCmd.Flags().BoolP("by-language", "l", false, "Show statistics by edition language")
+	statsCmd.Flags().BoolP("translated", "t", false, "Show translations by original and edition language")
 }
 
-func showBasicStats(db *sql.DB) {
-	var total, read int
-	err := db.QueryRow("SELECT COUNT(*) FROM books").Scan(&total)
-	if err != nil {
-		log.Fatal(err)
+type groupCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+type statsSummary struct {
+	Total  int `json:"total"`
+	Read   int `json:"read"`
+	Unread int `json:"unread"`
+}
+
+type translationCount struct {
+	From  string `json:"from"`
+	Into  string `json:"into"`
+	Count int    `json:"count"`
+}
+
+type translationSummary struct {
+	Translations int                `json:"translations"`
+	Originals    int                `json:"originals"`
+	Pairs        []translationCount `json:"pairs"`
+}
+
+type statsReport struct {
+	Summary      *statsSummary       `json:"summary,omitempty"`
+	ByYear       []groupCount        `json:"by_year,omitempty"`
+	ByAuthor     []groupCount        `json:"by_author,omitempty"`
+	ByStatus     []groupCount        `json:"by_status,omitempty"`
+	ByLanguage   []groupCount        `json:"by_language,omitempty"`
+	Translations *translationSummary `json:"translations,omitempty"`
+}
+
+// statsRow is one statistic in the flat layout used by csv, tsv and jsonl.
+type statsRow struct {
+	Section string `json:"section"`
+	Key     string `json:"key"`
+	Count   int    `json:"count"`
+}
+
+func (r statsReport) Items() any {
+	var rows []statsRow
+	if r.Summary != nil {
+		rows = append(rows,
+			statsRow{"summary", "total", r.Summary.Total},
+			statsRow{"summary", "read", r.Summary.Read},
+			statsRow{"summary", "unread", r.Summary.Unread})
+	}
+	for _, section := range []struct {
+		name   string
+		counts []groupCount
+	}{
+		{"by_year", r.ByYear}, {"by_author", r.ByAuthor},
+		{"by_status", r.ByStatus}, {"by_language", r.ByLanguage},
+	} {
+		for _, c := range section.counts {
+			rows = append(rows, statsRow{section.name, c.Key, c.Count})
+		}
 	}
+	if r.Translations != nil {
+		rows = append(rows,
+			statsRow{"translations", "translations", r.Translations.Translations},
+			statsRow{"translations", "originals", r.Translations.Originals})
+		for _, p := range r.Translations.Pairs {
+			rows = append(rows, statsRow{"translations", p.From + ">" + p.Into, p.Count})
+		}
+	}
+	return rows
+}
 
-	err = db.QueryRow("SELECT COUNT(*) FROM books WHERE status = 'read'").Scan(&read)
-	if err != nil {
-		log.Fatal(err)
+func (r statsReport) WriteTable(out io.Writer) error {
+	if s := r.Summary; s != nil {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w)
+		writeHeader(w, "STATISTIC", "VALUE")
+		fmt.Fprintf(w, "%s\t%s\t\n", i18n.T("Total books"), i18n.Number(float64(s.Total), 0))
+		fmt.Fprintf(w, "%s\t%s\t\n", i18n.T("Read"), share(s.Read, s.Total))
+		fmt.Fprintf(w, "%s\t%s\t\n", i18n.T("Unread"), share(s.Unread, s.Total))
+		w.Flush()
 	}
+	if r.ByYear != nil {
+		writeCounts(out, "YEAR", "", r.ByYear)
+	}
+	if r.ByAuthor != nil {
+		writeCounts(out, "AUTHOR", "", r.ByAuthor)
+	}
+	if r.ByStatus != nil {
+		writeCounts(out, "STATUS", "", r.ByStatus)
+	}
+	if r.ByLanguage != nil {
+		writeCounts(out, "LANGUAGE", i18n.T("(unknown)"), r.ByLanguage)
+	}
+	if t := r.Translations; t != nil {
+		total := t.Translations + t.Originals
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w)
+		writeHeader(w, "STATISTIC", "VALUE")
+		fmt.Fprintf(w, "%s\t%s\t\n", i18n.T("Translations"), share(t.Translations, total))
+		fmt.Fprintf(w, "%s\t%s\t\n", i18n.T("Originals"), share(t.Originals, total))
+		w.Flush()
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "\nSTATISTIC\tVALUE\t")
-	fmt.Fprintln(w, "---------\t-----\t")
-	fmt.Fprintf(w, "Total books\t%d\t\n", total)
-	fmt.Fprintf(w, "Read\t%d (%.0f%%)\t\n", read, float64(read)/float64(total)*100)
-	fmt.Fprintf(w, "Unread\t%d (%.0f%%)\t\n", total-read, float64(total-read)/float64(total)*100)
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w)
+		writeHeader(w, "FROM", "INTO", "COUNT")
+		for _, p := range t.Pairs {
+			into := p.Into
+			if into == "" {
+				into = i18n.T("(unknown)")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t\n", p.From, into, i18n.Number(float64(p.Count), 0))
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+// writeCounts prints one grouped count table; unknown replaces empty keys.
+func writeCounts(out io.Writer, heading, unknown string, counts []groupCount) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w)
+	writeHeader(w, heading, "COUNT")
+	for _, c := range counts {
+		key := c.Key
+		if key == "" {
+			key = unknown
+		}
+		fmt.Fprintf(w, "%s\t%s\t\n", key, i18n.Number(float64(c.Count), 0))
+	}
 	w.Flush()
 }
 
-func showYearStats(db *sql.DB) {
-	rows, err := db.Query(`
-		SELECT published_year, COUNT(*) as count 
-		FROM books 
-		GROUP BY published_year 
-		ORDER BY published_year DESC`)
+// share writes a part of a total with its percentage, e.g. "12 (40%)".
+func share(n, total int) string {
+	return fmt.Sprintf("%s (%s%%)", i18n.Number(float64(n), 0), i18n.Number(float64(n)/float64(total)*100, 0))
+}
+
+func basicStats(db *sql.DB) (*statsSummary, error) {
+	var s statsSummary
+	err := db.QueryRow("SELECT COUNT(*) FROM books").Scan(&s.Total)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "\nYEAR\tCOUNT\t")
-	fmt.Fprintln(w, "----\t-----\t")
-
-	var year, count int
-	for rows.Next() {
-		err := rows.Scan(&year, &count)
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Fprintf(w, "%d\t%d\t\n", year, count)
+	err = db.QueryRow("SELECT COUNT(*) FROM books WHERE status = 'read'").Scan(&s.Read)
+	if err != nil {
+		return nil, err
 	}
-	w.Flush()
+	s.Unread = s.Total - s.Read
+	return &s, nil
 }
 
-func showAuthorStats(db *sql.DB) {
-	rows, err := db.Query(`
-		SELECT author, COUNT(*) as count 
-		FROM books 
-		GROUP BY author 
-		ORDER BY count DESC`)
+// countBy runs a query returning (key, count) rows. Keys may be NULL or
+// numbers and are returned as text.
+func countBy(db *sql.DB, query string) ([]groupCount, error) {
+	rows, err := db.Query(query)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "\nAUTHOR\tCOUNT\t")
-	fmt.Fprintln(w, "------\t-----\t")
-
-	var author string
-	var count int
+	counts := []groupCount{}
 	for rows.Next() {
-		err := rows.Scan(&author, &count)
+		var key sql.NullString
+		var count int
+		err := rows.Scan(&key, &count)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
-		fmt.Fprintf(w, "%s\t%d\t\n", author, count)
+		counts = append(counts, groupCount{Key: key.String, Count: count})
 	}
-	w.Flush()
+	return counts, rows.Err()
 }
 
-func showStatusStats(db *sql.DB) {
+func translationStats(db *sql.DB) (*translationSummary, error) {
+	var total int
+	t := &translationSummary{Pairs: []translationCount{}}
+	err := db.QueryRow("SELECT COUNT(*) FROM books").Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM books 
+		WHERE original_language != '' AND original_language != language`).Scan(&t.Translations)
+	if err != nil {
+		return nil, err
+	}
+	t.Originals = total - t.Translations
+
 	rows, err := db.Query(`
-		SELECT status, COUNT(*) as count 
+		SELECT original_language, language, COUNT(*) as count 
 		FROM books 
-		GROUP BY status`)
+		WHERE original_language != '' AND original_language != language 
+		GROUP BY original_language, language 
+		ORDER BY count DESC`)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "\nSTATUS\tCOUNT\t")
-	fmt.Fprintln(w, "------\t-----\t")
-
-	var status string
-	var count int
 	for rows.Next() {
-		err := rows.Scan(&status, &count)
+		var p translationCount
+		err := rows.Scan(&p.From, &p.Into, &p.Count)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
-		fmt.Fprintf(w, "%s\t%d\t\n", status, count)
+		t.Pairs = append(t.Pairs, p)
 	}
-	w.Flush()
+	return t, rows.Err()
 }
@@ -1,41 +1,68 @@
 package cmd
 
 import (
-	"fmt"
-	"log"
-	"strconv"
-
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
 	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
 	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
 	"github.com/spf13/cobra"
 )
 
 var updateCmd = &cobra.Command{
-	Use:   "update <id>",
+	Use:   "update <id|title|range|->...",
 	Short: "Update status a book by ID",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Update books.
+
+Without field flags the books are marked as read. With field flags only
+the given fields are changed.
+
+` + bulkHelp + ` A single book may also be given by title or author, as
+in show.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeBooks,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		db, err := db.InitDB()
 		if err != nil {
-			log.Fatalf("Failed to connect to database: %v", err)
+			return i18n.Errorf("failed to connect to database: %w", err)
 		}
 		defer db.Close()
 
-		id, err := strconv.Atoi(args[0])
+		repo := repository.NewBookRepository(db)
+		ids, single, err := bulkBookIDs(cmd, repo, args, true)
 		if err != nil {
-			log.Fatalf("Invalid ID format: %v", err)
+			return i18n.Errorf("failed to find book: %w", err)
+		}
+		if err := confirmBulk(cmd, len(ids), "this would update %d book; add --yes to confirm", "this would update %d books; add --yes to confirm"); err != nil {
+			return i18n.Errorf("failed to update books: %w", err)
 		}
 
-		repo := repository.NewBookRepository(db)
-		err = repo.UpdateStatusBook(id)
-		if err != nil {
-			log.Fatalf("Failed to update book: %v", err)
+		// Without field flags the command keeps its original meaning:
+		// mark the books as read.
+		if !bookFieldFlagsChanged(cmd) {
+			if err := repo.MarkAsRead(ids); err != nil {
+				return i18n.Errorf("failed to update book: %w", err)
+			}
+			if single {
+				return printMessage(cmd, ids[0], "Status book with ID %d update successfully", ids[0])
+			}
+			return printBulkMessage(cmd, ids, "%d book marked as read (IDs %s)", "%d books marked as read (IDs %s)")
 		}
 
-		fmt.Printf("Status book with ID %d update successfully\n", id)
+		err = repo.UpdateBooks(ids, func(book *models.Book) error {
+			return applyBookFieldFlags(cmd, book)
+		})
+		if err != nil {
+			return i18n.Errorf("failed to update book: %w", err)
+		}
+		if single {
+			return printMessage(cmd, ids[0], "Book with ID %d updated successfully", ids[0])
+		}
+		return printBulkMessage(cmd, ids, "%d book updated (IDs %s)", "%d books updated (IDs %s)")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
+	addBookFieldFlags(updateCmd)
+	addBulkFlags(updateCmd)
 }
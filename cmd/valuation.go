@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var valuationCmd = &cobra.Command{
+	Use:   "valuation",
+	Short: "Print a valuation report of the collection",
+	Long: `Print a valuation report of the collection for insurance purposes.
+
+Every priced book is listed with its purchase price and value, followed by
+totals per currency, per location and per format. The value of a book is
+its estimated value when set and its purchase price otherwise. Amounts in
+different currencies are never added up.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		repo := repository.NewBookRepository(db)
+		books, err := repo.GetAllBooks()
+		if err != nil {
+			return i18n.Errorf("failed to find books: %w", err)
+		}
+
+		report := valuationReport{Date: time.Now().Format(time.DateOnly), TotalBooks: len(books)}
+		for _, book := range books {
+			if book.Currency != "" {
+				report.Books = append(report.Books, valuationLine{
+					ID:       book.ID,
+					Title:    book.Title,
+					Author:   book.Author,
+					Location: book.Location,
+					Format:   book.Format,
+					Purchase: book.PurchaseDate,
+					Price:    book.PurchasePrice,
+					Value:    book.Value(),
+					Currency: book.Currency,
+				})
+			}
+		}
+		report.ByCurrency = sumValuation(report.Books, func(valuationLine) string { return "" })
+		report.ByLocation = sumValuation(report.Books, func(l valuationLine) string { return l.Location })
+		report.ByFormat = sumValuation(report.Books, func(l valuationLine) string { return l.Format })
+
+		return render(cmd, report)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(valuationCmd)
+}
+
+type valuationLine struct {
+	ID       int           `json:"id"`
+	Title    string        `json:"title"`
+	Author   string        `json:"author"`
+	Location string        `json:"location"`
+	Format   string        `json:"format"`
+	Purchase string        `json:"purchase_date"`
+	Price    models.Amount `json:"purchase_price"`
+	Value    models.Amount `json:"value"`
+	Currency string        `json:"currency"`
+}
+
+type valuationTotal struct {
+	Group    string        `json:"group,omitempty"`
+	Currency string        `json:"currency"`
+	Books    int           `json:"books"`
+	Paid     models.Amount `json:"paid"`
+	Value    models.Amount `json:"value"`
+}
+
+type valuationReport struct {
+	Date       string           `json:"date"`
+	TotalBooks int              `json:"total_books"`
+	Books      []valuationLine  `json:"books"`
+	ByCurrency []valuationTotal `json:"by_currency"`
+	ByLocation []valuationTotal `json:"by_location"`
+	ByFormat   []valuationTotal `json:"by_format"`
+}
+
+// Items makes csv and similar formats list the per-book lines.
+func (r valuationReport) Items() any {
+	return r.Books
+}
+
+func (r valuationReport) WriteTable(out io.Writer) error {
+	fmt.Fprintln(out, i18n.Sprintf("COLLECTION VALUATION REPORT — %s", formatDate(r.Date)))
+	fmt.Fprintln(out, i18n.N(r.TotalBooks, "%d of %d book priced", "%d of %d books priced", len(r.Books), r.TotalBooks))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w)
+	writeHeader(w, "ID", "TITLE", "AUTHOR", "LOCATION", "FORMAT", "PURCHASED", "PRICE", i18n.TC("amount", "VALUE"))
+	for _, l := range r.Books {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s %s\t%s %s\t\n",
+			l.ID, l.Title, l.Author, l.Location, l.Format, formatDate(l.Purchase),
+			formatAmount(l.Price), l.Currency, formatAmount(l.Value), l.Currency)
+	}
+	w.Flush()
+
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w)
+	writeHeader(w, "CURRENCY", "BOOKS", "PAID", i18n.TC("amount", "VALUE"))
+	for _, t := range r.ByCurrency {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t\n", t.Currency, t.Books, formatAmount(t.Paid), formatAmount(t.Value))
+	}
+	w.Flush()
+
+	writeValuationTotals(out, "LOCATION", r.ByLocation)
+	writeValuationTotals(out, "FORMAT", r.ByFormat)
+	return nil
+}
+
+// sumValuation totals lines per group and currency, where the group of a
+// line is given by key. Results are sorted by group, then currency.
+func sumValuation(lines []valuationLine, key func(valuationLine) string) []valuationTotal {
+	index := map[[2]string]int{}
+	var totals []valuationTotal
+	for _, l := range lines {
+		k := [2]string{key(l), l.Currency}
+		i, ok := index[k]
+		if !ok {
+			i = len(totals)
+			index[k] = i
+			totals = append(totals, valuationTotal{Group: k[0], Currency: k[1]})
+		}
+		totals[i].Books++
+		totals[i].Paid += l.Price
+		totals[i].Value += l.Value
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Group != totals[j].Group {
+			return totals[i].Group < totals[j].Group
+		}
+		return totals[i].Currency < totals[j].Currency
+	})
+	return totals
+}
+
+func writeValuationTotals(out io.Writer, heading string, totals []valuationTotal) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w)
+	writeHeader(w, heading, "CURRENCY", "BOOKS", "PAID", i18n.TC("amount", "VALUE"))
+	for _, t := range totals {
+		group := t.Group
+		if group == "" {
+			group = i18n.T("(unknown)")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t\n", group, t.Currency, t.Books, formatAmount(t.Paid), formatAmount(t.Value))
+	}
+	w.Flush()
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Save and run searches",
+	Long: `Save and run searches.
+
+A view is a filter expression and sort order, as taken by list, saved
+under a name:
+
+  book view save classics --where 'year < 1900 and status = unread' --sort author,year
+  book view run classics
+
+Views are stored in the library and run on its current books every time.
+They also appear as tabs in book interactive.`,
+}
+
+var viewSaveCmd = &cobra.Command{
+	Use:               "save <name>",
+	Short:             "Save a view, replacing one of the same name",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePositional(completeViews),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		view := models.View{Name: args[0]}
+		view.Where, _ = cmd.Flags().GetString("where")
+		view.Sort, _ = cmd.Flags().GetString("sort")
+
+		repo := repository.NewBookRepository(db)
+		if err := repo.SaveView(view); err != nil {
+			return i18n.Errorf("failed to save view: %w", err)
+		}
+		return printMessage(cmd, 0, "View %q saved", view.Name)
+	},
+}
+
+var viewRunCmd = &cobra.Command{
+	Use:               "run <name>",
+	Short:             "List the books of a view",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePositional(completeViews),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		view, err := repository.NewBookRepository(db).GetView(args[0])
+		db.Close()
+		if err != nil {
+			return i18n.Errorf("failed to find view: %w", err)
+		}
+
+		opts := repository.ListOptions{Where: view.Where, Sort: view.Sort}
+		opts.Limit, _ = cmd.Flags().GetInt("limit")
+		return listBooks(cmd, opts)
+	},
+}
+
+var viewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the saved views",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		views, err := repository.NewBookRepository(db).GetViews()
+		if err != nil {
+			return i18n.Errorf("failed to find views: %w", err)
+		}
+		if views == nil {
+			views = []models.View{}
+		}
+		return render(cmd, viewList(views))
+	},
+}
+
+var viewDeleteCmd = &cobra.Command{
+	Use:               "delete <name>",
+	Short:             "Delete a saved view",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePositional(completeViews),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := db.InitDB()
+		if err != nil {
+			return i18n.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		if err := repository.NewBookRepository(db).DeleteView(args[0]); err != nil {
+			return i18n.Errorf("failed to delete view: %w", err)
+		}
+		return printMessage(cmd, 0, "View %q deleted", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(viewCmd)
+	viewCmd.AddCommand(viewSaveCmd, viewRunCmd, viewListCmd, viewDeleteCmd)
+
+	viewSaveCmd.Flags().StringP("where", "w", "", "Filter expression, e.g. 'status = unread and year < 1900'")
+	viewSaveCmd.Flags().String("sort", "", "Sort fields, e.g. title,-year (default: id)")
+	viewSaveCmd.RegisterFlagCompletionFunc("sort", completeSort)
+
+	viewRunCmd.Flags().IntP("limit", "n", 0, "Maximum number of books to show (0: all)")
+}
+
+type viewList []models.View
+
+func (l viewList) WriteTable(out io.Writer) error {
+	if len(l) == 0 {
+		_, err := fmt.Fprintln(out, i18n.T("No views saved"))
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	writeHeader(w, "NAME", "WHERE", "SORT")
+	for _, v := range l {
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", v.Name, v.Where, v.Sort)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,312 @@
+// Package config reads and writes the user configuration file.
+//
+// The file is TOML. Keys are addressed with dots, so "list.sort" is the key
+// sort in the table [list] and "output" a key before the first table. Set
+// rewrites only the lines of the key it changes, keeping the comments and
+// layout of the file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DefaultPath returns the location of the configuration file when none is
+// given: book/config.toml in $XDG_CONFIG_HOME, or in the platform's
+// configuration directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "book", "config.toml"), nil
+}
+
+// File is a configuration file.
+type File struct {
+	Path   string
+	lines  []string
+	values map[string]string // the value of every key as a flag would take it
+}
+
+// Load reads the configuration file at path. A missing file is an empty
+// configuration.
+func Load(path string) (*File, error) {
+	f := &File{Path: path, values: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		f.lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	if err := f.parse(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parse decodes the lines of the file into its values.
+func (f *File) parse() error {
+	var doc map[string]any
+	if _, err := toml.Decode(strings.Join(f.lines, "\n"), &doc); err != nil {
+		return fmt.Errorf("%s: %w", f.Path, err)
+	}
+	values := make(map[string]string)
+	if err := flatten(values, "", doc); err != nil {
+		return fmt.Errorf("%s: %w", f.Path, err)
+	}
+	f.values = values
+	return nil
+}
+
+// flatten adds the values of table to values under their dotted keys,
+// prefixed with prefix.
+func flatten(values map[string]string, prefix string, table map[string]any) error {
+	for name, value := range table {
+		key := prefix + name
+		switch v := value.(type) {
+		case map[string]any:
+			if err := flatten(values, key+".", v); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				text, ok := scalar(item)
+				if !ok {
+					return fmt.Errorf("%s: arrays may only hold strings, numbers and booleans", key)
+				}
+				items[i] = text
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			text, ok := scalar(value)
+			if !ok {
+				return fmt.Errorf("%s: arrays of tables are not supported", key)
+			}
+			values[key] = text
+		}
+	}
+	return nil
+}
+
+// scalar returns a decoded TOML value other than a table or an array as a
+// flag would take it.
+func scalar(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case time.Time:
+		return v.Format(time.RFC3339), true
+	}
+	return "", false
+}
+
+// Get returns the value of key; arrays are joined with commas.
+func (f *File) Get(key string) (string, bool) {
+	value, ok := f.values[key]
+	return value, ok
+}
+
+// Keys returns the keys set in the file, sorted.
+func (f *File) Keys() []string {
+	keys := make([]string, 0, len(f.values))
+	for key := range f.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set sets key to value, which is a string, an int, a bool or a []string.
+// A key the file sets in a way Set cannot rewrite, such as inside an
+// inline table, is reported as an error and left unchanged.
+func (f *File) Set(key string, value any) error {
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+	line := formatKey(name) + " = " + formatValue(value)
+
+	l := f.layout()
+	lines := slices.Clone(f.lines)
+	if s, ok := l.keys[key]; ok {
+		lines = slices.Replace(lines, s[0], s[1], line)
+	} else if end, ok := l.ends[table]; ok {
+		// A new key goes at the end of its table.
+		insert := []string{line}
+		if table == "" && end < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[end]), "[") {
+			insert = append(insert, "")
+		}
+		lines = slices.Insert(lines, end, insert...)
+	} else {
+		if len(lines) > 0 && lines[len(lines)-1] != "" {
+			lines = append(lines, "")
+		}
+		parts := strings.Split(table, ".")
+		for i, part := range parts {
+			parts[i] = formatKey(part)
+		}
+		lines = append(lines, "["+strings.Join(parts, ".")+"]", line)
+	}
+
+	old := f.lines
+	f.lines = lines
+	if err := f.parse(); err != nil {
+		f.lines = old
+		return fmt.Errorf("cannot set %s in %s, change it there instead", key, f.Path)
+	}
+	return nil
+}
+
+// layout locates the keys and tables of the file by line.
+type layout struct {
+	keys map[string][2]int // first line and the line after the value of every key
+	ends map[string]int    // line after the last key of every table, "" for the keys before the first table
+}
+
+func (f *File) layout() layout {
+	l := layout{keys: make(map[string][2]int), ends: map[string]int{"": 0}}
+	table, inTable := "", true
+	for i := 0; i < len(f.lines); i++ {
+		line := strings.TrimSpace(f.lines[i])
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "["):
+			// Keys in arrays of tables cannot be set.
+			name, rest, ok := parseKey(line[1:])
+			table, inTable = name, ok && strings.HasPrefix(rest, "]")
+			if inTable {
+				l.ends[table] = i + 1
+			}
+		default:
+			end := valueEnd(f.lines, i)
+			if name, rest, ok := parseKey(line); ok && strings.HasPrefix(rest, "=") && inTable {
+				key := name
+				if table != "" {
+					key = table + "." + name
+				}
+				l.keys[key] = [2]int{i, end}
+				l.ends[table] = end
+			}
+			i = end - 1
+		}
+	}
+	return l
+}
+
+// valueEnd returns the line after the key and value starting at line i,
+// whose value may continue over several lines.
+func valueEnd(lines []string, i int) int {
+	for end := i + 1; end <= len(lines); end++ {
+		var v map[string]any
+		if _, err := toml.Decode(strings.Join(lines[i:end], "\n"), &v); err == nil {
+			return end
+		}
+	}
+	return i + 1
+}
+
+// parseKey parses the dotted key at the start of s, whose parts may be
+// quoted, and returns it with its parts joined by dots and the rest of s.
+func parseKey(s string) (key, rest string, ok bool) {
+	var parts []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		var part string
+		switch {
+		case strings.HasPrefix(s, `"`):
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return "", "", false
+			}
+			var err error
+			if part, err = strconv.Unquote(s[:end+1]); err != nil {
+				return "", "", false
+			}
+			s = s[end+1:]
+		case strings.HasPrefix(s, "'"):
+			end := strings.IndexByte(s[1:], '\'')
+			if end < 0 {
+				return "", "", false
+			}
+			part, s = s[1:end+1], s[end+2:]
+		default:
+			end := strings.IndexFunc(s, func(r rune) bool { return !bareKeyRune(r) })
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return "", "", false
+			}
+			part, s = s[:end], s[end:]
+		}
+		parts = append(parts, part)
+		s = strings.TrimLeft(s, " \t")
+		if !strings.HasPrefix(s, ".") {
+			return strings.Join(parts, "."), s, true
+		}
+		s = s[1:]
+	}
+}
+
+func bareKeyRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-'
+}
+
+// formatKey writes one part of a key, quoted unless it is a bare key.
+func formatKey(name string) string {
+	if name == "" || strings.IndexFunc(name, func(r rune) bool { return !bareKeyRune(r) }) >= 0 {
+		return strconv.Quote(name)
+	}
+	return name
+}
+
+// Save writes the file, creating its directory when needed.
+func (f *File) Save() error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, []byte(strings.Join(f.lines, "\n")+"\n"), 0o644)
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = strconv.Quote(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,76 @@
+// Package covers keeps book cover images in a content-addressed directory:
+// every image is stored once under the SHA-256 of its bytes.
+package covers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Store copies the image at src into dir and returns the name it was stored under.
+func Store(dir, src string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("%s is not a supported image (png, jpeg, gif): %v", src, err)
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:]) + "." + format
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return name, nil
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated
+	// image under a valid hash.
+	tmp, err := os.CreateTemp(dir, ".cover-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	return name, os.Rename(tmp.Name(), path)
+}
+
+// Export copies the stored cover name from dir to dst.
+func Export(dir, name, dst string) error {
+	in, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+// Error reports a problem in a filter expression together with the
+// position of the offending token.
+type Error struct {
+	Input  string
+	Pos    int // byte offset of the offending token
+	Length int // byte length of the offending token
+	Msg    string
+}
+
+func newError(input string, pos, length int, format string, args ...any) *Error {
+	return &Error{Input: input, Pos: pos, Length: length, Msg: i18n.Sprintf(format, args...)}
+}
+
+// Column returns the 1-based character column of the offending token.
+func (e *Error) Column() int {
+	return utf8.RuneCountInString(e.Input[:e.Pos]) + 1
+}
+
+// Error renders the message followed by the expression with the offending
+// token underlined:
+//
+//	column 1: unknown field "autor"
+//	  autor ~ "tolkien"
+//	  ^^^^^
+func (e *Error) Error() string {
+	width := utf8.RuneCountInString(e.Input[e.Pos : e.Pos+e.Length])
+	if width == 0 {
+		width = 1
+	}
+	return fmt.Sprintf("%s\n  %s\n  %s%s",
+		i18n.Sprintf("column %d: %s", e.Column(), e.Msg), e.Input,
+		strings.Repeat(" ", e.Column()-1), strings.Repeat("^", width))
+}
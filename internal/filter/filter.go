@@ -0,0 +1,337 @@
+// Package filter implements the --where expression language, e.g.
+//
+//	author ~ "tolkien" and year >= 1950 and status != read
+//
+// Expressions are compiled into a parameterized SQL condition over the
+// books table; values never end up in the SQL text itself.
+//
+// Grammar:
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field op value
+//	op         = "=" | "!=" | "<" | "<=" | ">" | ">=" | "~" | "!~"
+//	value      = quoted string | number | bare word
+//
+// "~" matches a substring case-insensitively, "!~" is its negation. Text
+// comparisons ignore case in every script, not just ASCII. "~" also accepts
+// text typed in the wrong keyboard layout, and for title, original_title and
+// author a spelling in the other script: author ~ tolstoy finds "Толстой".
+//
+// A field without a value equals "" (0 for numbers) but is neither less
+// nor greater than anything: finished = "" finds the books never read,
+// finished < 2020-01-01 only those read before 2020.
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/translit"
+)
+
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	numberField
+	moneyField
+	dateField
+)
+
+type field struct {
+	column string
+	kind   fieldKind
+	// authorAliases makes text comparisons also match every name of the
+	// book's author record.
+	authorAliases bool
+	// nullable columns date back to the original schema and may hold NULL.
+	nullable bool
+	// key is the column holding the transliteration key of the field, see
+	// package translit. For author it covers the aliases too.
+	key string
+}
+
+var fields = map[string]field{
+	"id":                {column: "id", kind: numberField},
+	"title":             {column: "title", kind: textField, key: "title_key"},
+	"author":            {column: "author", kind: textField, authorAliases: true, key: "author_key"},
+	"year":              {column: "published_year", kind: numberField, nullable: true},
+	"status":            {column: "status", kind: textField, nullable: true},
+	"publisher":         {column: "publisher", kind: textField},
+	"language":          {column: "language", kind: textField},
+	"original_language": {column: "original_language", kind: textField},
+	"original_title":    {column: "original_title", kind: textField, key: "original_title_key"},
+	"location":          {column: "location", kind: textField},
+	"format":            {column: "format", kind: textField},
+	"notes":             {column: "notes", kind: textField},
+	"isbn":              {column: "isbn", kind: textField},
+	"pages":             {column: "pages", kind: numberField},
+	"purchased":         {column: "purchase_date", kind: dateField},
+	"finished":          {column: "read_date", kind: dateField},
+	"price":             {column: "purchase_price", kind: moneyField},
+	"currency":          {column: "currency", kind: textField},
+	"value":             {column: "CASE WHEN estimated_value != 0 THEN estimated_value ELSE purchase_price END", kind: moneyField},
+}
+
+// Fields returns the field names usable in expressions, sorted.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Compile parses a filter expression and returns the equivalent SQL
+// condition and its arguments. An empty expression matches every book and
+// compiles to an empty condition. Syntax errors are returned as *Error.
+func Compile(input string) (string, []any, error) {
+	if strings.TrimSpace(input) == "" {
+		return "", nil, nil
+	}
+	tokens, err := lex(input)
+	if err != nil {
+		return "", nil, err
+	}
+	p := &parser{input: input, tokens: tokens}
+	cond, err := p.parseOr()
+	if err != nil {
+		return "", nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return "", nil, p.errorAt(tok, `unexpected ")" without matching "("`)
+		}
+		return "", nil, p.errorAt(tok, `unexpected %s %q, expected "and", "or" or end of expression`, tok.kind, tok.text)
+	}
+	return cond, p.args, nil
+}
+
+// Quote returns s as a string literal of the expression language.
+func Quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+	args   []any
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorAt(tok token, format string, args ...any) error {
+	return newError(p.input, tok.pos, len(tok.text), format, args...)
+}
+
+func (p *parser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " OR " + right + ")"
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (string, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return "", err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " AND " + right + ")"
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (string, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNot:
+		p.next()
+		cond, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + cond, nil
+	case tokLParen:
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			if closing.kind == tokEOF {
+				return "", p.errorAt(tok, `missing ")" for this "("`)
+			}
+			return "", p.errorAt(closing, `expected ")", got %s %q`, closing.kind, closing.text)
+		}
+		return cond, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (string, error) {
+	nameTok := p.next()
+	if nameTok.kind != tokIdent {
+		if nameTok.kind == tokEOF {
+			return "", p.errorAt(nameTok, "expected a field name, got end of expression")
+		}
+		return "", p.errorAt(nameTok, "expected a field name, got %s %q", nameTok.kind, nameTok.text)
+	}
+	f, ok := fields[strings.ToLower(nameTok.text)]
+	if !ok {
+		return "", p.errorAt(nameTok, "unknown field %q (valid: %s)", nameTok.text, strings.Join(Fields(), ", "))
+	}
+
+	opTok := p.next()
+	if opTok.kind != tokOp {
+		return "", p.errorAt(opTok, "expected an operator (=, !=, <, <=, >, >=, ~, !~) after %q", nameTok.text)
+	}
+
+	valueTok := p.next()
+	switch valueTok.kind {
+	case tokString, tokNumber, tokIdent:
+	case tokEOF:
+		return "", p.errorAt(valueTok, "expected a value after %q", opTok.text)
+	default:
+		return "", p.errorAt(valueTok, "expected a value after %q, got %s %q", opTok.text, valueTok.kind, valueTok.text)
+	}
+
+	switch f.kind {
+	case numberField, moneyField:
+		return p.compileNumber(f, opTok, valueTok)
+	case dateField:
+		if valueTok.val != "" {
+			if _, err := time.Parse(time.DateOnly, valueTok.val); err != nil {
+				return "", p.errorAt(valueTok, "%q is not a date, expected YYYY-MM-DD", valueTok.val)
+			}
+		}
+	}
+	return p.compileText(f, opTok.val, valueTok.val), nil
+}
+
+func (p *parser) compileNumber(f field, opTok, valueTok token) (string, error) {
+	if opTok.val == "~" || opTok.val == "!~" {
+		return "", p.errorAt(opTok, "operator %q only applies to text fields", opTok.val)
+	}
+
+	var value int64
+	var err error
+	if f.kind == moneyField {
+		var amount models.Amount
+		amount, err = models.ParseAmount(valueTok.val)
+		value = int64(amount)
+	} else {
+		value, err = strconv.ParseInt(valueTok.val, 10, 64)
+	}
+	if err != nil {
+		return "", p.errorAt(valueTok, "%q is not a number", valueTok.val)
+	}
+
+	p.args = append(p.args, value)
+	if opTok.val == "=" || opTok.val == "!=" {
+		// An unknown number equals 0, so "year = 0" finds the books
+		// without a year.
+		return fmt.Sprintf("COALESCE(%s, 0) %s ?", f.column, opTok.val), nil
+	}
+	return fmt.Sprintf("%s %s ?", f.column, opTok.val), nil
+}
+
+func (p *parser) compileText(f field, op, value string) string {
+	negate := op == "!=" || op == "!~"
+	var cond string
+	switch op {
+	case "~", "!~":
+		cond = p.contains(f, value)
+	case "=", "!=":
+		cond = p.match(f, "%s = ? COLLATE UNICODE", value)
+	default:
+		// A missing value has no order: NULL, and the empty text that
+		// stands for it in the newer columns, never compares, so books
+		// never read do not match "finished < 2020-01-01".
+		p.args = append(p.args, value)
+		cond = fmt.Sprintf("NULLIF(%s, '') %s ?", f.column, op)
+		if f.kind == textField {
+			cond += " COLLATE UNICODE"
+		}
+	}
+	if negate {
+		return "NOT " + cond
+	}
+	return cond
+}
+
+// contains builds the condition of "~": the value is a substring of the
+// field, also when typed in the other keyboard layout, or its transliteration
+// key is part of the field's key.
+func (p *parser) contains(f field, value string) string {
+	const like = "unicode_fold(%s) LIKE unicode_fold(?) ESCAPE '\\'"
+	values := []string{value}
+	if swapped := translit.SwapLayout(value); swapped != value {
+		values = append(values, swapped)
+	}
+	var conds []string
+	for _, v := range values {
+		conds = append(conds, p.match(f, like, "%"+escapeLike(v)+"%"))
+	}
+	if f.key != "" {
+		for _, v := range values {
+			if k := translit.Key(v); k != "" {
+				p.args = append(p.args, "%"+escapeLike(k)+"%")
+				conds = append(conds, f.key+" LIKE ? ESCAPE '\\'")
+			}
+		}
+	}
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
+
+// match builds a condition from predicate, a format with %s in place of the
+// column, extended to the author's aliases for fields that have them.
+func (p *parser) match(f field, predicate string, value string) string {
+	p.args = append(p.args, value)
+	cond := fmt.Sprintf(predicate, "COALESCE("+f.column+", '')")
+	if f.authorAliases {
+		p.args = append(p.args, value)
+		cond = fmt.Sprintf("(%s OR author_id IN (SELECT author_id FROM author_aliases WHERE %s))",
+			cond, fmt.Sprintf(predicate, "alias"))
+	}
+	return "(" + cond + ")"
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,239 @@
+package filter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []token
+	}{
+		{"", []token{{kind: tokEOF, pos: 0}}},
+		{`title ~ "the hobbit"`, []token{
+			{kind: tokIdent, text: "title", val: "title", pos: 0},
+			{kind: tokOp, text: "~", val: "~", pos: 6},
+			{kind: tokString, text: `"the hobbit"`, val: "the hobbit", pos: 8},
+			{kind: tokEOF, pos: 20},
+		}},
+		{"year>=1950", []token{
+			{kind: tokIdent, text: "year", val: "year", pos: 0},
+			{kind: tokOp, text: ">=", val: ">=", pos: 4},
+			{kind: tokNumber, text: "1950", val: "1950", pos: 6},
+			{kind: tokEOF, pos: 10},
+		}},
+		{`not (a != 'x\'y') OR b`, []token{
+			{kind: tokNot, text: "not", val: "not", pos: 0},
+			{kind: tokLParen, text: "(", val: "(", pos: 4},
+			{kind: tokIdent, text: "a", val: "a", pos: 5},
+			{kind: tokOp, text: "!=", val: "!=", pos: 7},
+			{kind: tokString, text: `'x\'y'`, val: "x'y", pos: 10},
+			{kind: tokRParen, text: ")", val: ")", pos: 16},
+			{kind: tokOr, text: "OR", val: "OR", pos: 18},
+			{kind: tokIdent, text: "b", val: "b", pos: 21},
+			{kind: tokEOF, pos: 22},
+		}},
+		{"finished < 2020-01-05 and price <= 12.50 and language = en-GB", []token{
+			{kind: tokIdent, text: "finished", val: "finished", pos: 0},
+			{kind: tokOp, text: "<", val: "<", pos: 9},
+			{kind: tokIdent, text: "2020-01-05", val: "2020-01-05", pos: 11},
+			{kind: tokAnd, text: "and", val: "and", pos: 22},
+			{kind: tokIdent, text: "price", val: "price", pos: 26},
+			{kind: tokOp, text: "<=", val: "<=", pos: 32},
+			{kind: tokNumber, text: "12.50", val: "12.50", pos: 35},
+			{kind: tokAnd, text: "and", val: "and", pos: 41},
+			{kind: tokIdent, text: "language", val: "language", pos: 45},
+			{kind: tokOp, text: "=", val: "=", pos: 54},
+			{kind: tokIdent, text: "en-GB", val: "en-GB", pos: 56},
+			{kind: tokEOF, pos: 61},
+		}},
+		{"автор = -3", []token{
+			{kind: tokIdent, text: "автор", val: "автор", pos: 0},
+			{kind: tokOp, text: "=", val: "=", pos: 11},
+			{kind: tokNumber, text: "-3", val: "-3", pos: 13},
+			{kind: tokEOF, pos: 15},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := lex(tt.input)
+		if err != nil {
+			t.Errorf("lex(%q): %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lex(%q) =\n%+v\nwant\n%+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIsNumber(t *testing.T) {
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"1950", true},
+		{"-3", true},
+		{"12.50", true},
+		{".5", false},
+		{"1.2.3", false},
+		{"-", false},
+		{"2020-01-05", false},
+		{"en-GB", false},
+	}
+	for _, tt := range tests {
+		if got := isNumber(tt.word); got != tt.want {
+			t.Errorf("isNumber(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input string
+		sql   string
+		args  []any
+	}{
+		{"", "", nil},
+		{"  ", "", nil},
+		{"status = read", "(COALESCE(status, '') = ? COLLATE UNICODE)", []any{"read"}},
+		{"STATUS != read", "NOT (COALESCE(status, '') = ? COLLATE UNICODE)", []any{"read"}},
+		{"year >= 1950", "published_year >= ?", []any{int64(1950)}},
+		{"year = 0", "COALESCE(published_year, 0) = ?", []any{int64(0)}},
+		{"year != 0", "COALESCE(published_year, 0) != ?", []any{int64(0)}},
+		{"pages < 300", "pages < ?", []any{int64(300)}},
+		{"price <= 12.50", "purchase_price <= ?", []any{int64(1250)}},
+		{"publisher < m", "NULLIF(publisher, '') < ? COLLATE UNICODE", []any{"m"}},
+		{"status > r", "NULLIF(status, '') > ? COLLATE UNICODE", []any{"r"}},
+		{"finished < 2020-01-01", "NULLIF(read_date, '') < ?", []any{"2020-01-01"}},
+		{"purchased >= '2019-05-01'", "NULLIF(purchase_date, '') >= ?", []any{"2019-05-01"}},
+		{`finished = ""`, "(COALESCE(read_date, '') = ? COLLATE UNICODE)", []any{""}},
+		{`finished != ""`, "NOT (COALESCE(read_date, '') = ? COLLATE UNICODE)", []any{""}},
+		{"author = tolkien", "((COALESCE(author, '') = ? COLLATE UNICODE OR author_id IN (SELECT author_id FROM author_aliases WHERE alias = ? COLLATE UNICODE)))",
+			[]any{"tolkien", "tolkien"}},
+		{`notes ~ "50%"`, `(unicode_fold(COALESCE(notes, '')) LIKE unicode_fold(?) ESCAPE '\')`, []any{`%50\%%`}},
+		{"isbn !~ 978", `NOT (unicode_fold(COALESCE(isbn, '')) LIKE unicode_fold(?) ESCAPE '\')`, []any{"%978%"}},
+	}
+	for _, tt := range tests {
+		sql, args, err := Compile(tt.input)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.input, err)
+			continue
+		}
+		if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("Compile(%q) =\n%s %#v\nwant\n%s %#v", tt.input, sql, args, tt.sql, tt.args)
+		}
+	}
+}
+
+func TestCompilePrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		sql   string
+	}{
+		{"year = 1 or year = 2 and year = 3",
+			"(COALESCE(published_year, 0) = ? OR (COALESCE(published_year, 0) = ? AND COALESCE(published_year, 0) = ?))"},
+		{"(year = 1 or year = 2) and year = 3",
+			"((COALESCE(published_year, 0) = ? OR COALESCE(published_year, 0) = ?) AND COALESCE(published_year, 0) = ?)"},
+		{"not year = 1 and year = 2",
+			"(NOT COALESCE(published_year, 0) = ? AND COALESCE(published_year, 0) = ?)"},
+		{"not not (year = 1)", "NOT NOT COALESCE(published_year, 0) = ?"},
+	}
+	for _, tt := range tests {
+		sql, args, err := Compile(tt.input)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.input, err)
+			continue
+		}
+		if sql != tt.sql {
+			t.Errorf("Compile(%q) =\n%s\nwant\n%s", tt.input, sql, tt.sql)
+		}
+		if want := []any{int64(1), int64(2), int64(3)}[:len(args)]; !reflect.DeepEqual(args, want) {
+			t.Errorf("Compile(%q) args = %v, want %v", tt.input, args, want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		column int
+		length int
+		msg    string
+	}{
+		{"autor ~ tolkien", 1, 5, `unknown field "autor"`},
+		{`title ~ "hobbit`, 9, 7, "unterminated string"},
+		{"title ! hobbit", 7, 1, `unexpected "!"`},
+		{"title # hobbit", 7, 1, `unexpected character "#"`},
+		{"title hobbit", 7, 6, "expected an operator"},
+		{"title =", 8, 0, `expected a value after "="`},
+		{"title = (", 9, 1, `expected a value after "=", got "(" "("`},
+		{"= hobbit", 1, 1, `expected a field name, got operator "="`},
+		{"title = a and", 14, 0, "expected a field name, got end of expression"},
+		{"(title = a", 1, 1, `missing ")" for this "("`},
+		{"(title = a title", 12, 5, `expected ")", got word "title"`},
+		{"title = a)", 10, 1, `unexpected ")" without matching "("`},
+		{"title = a b", 11, 1, `unexpected word "b", expected "and", "or" or end of expression`},
+		{"year ~ 19", 6, 1, `operator "~" only applies to text fields`},
+		{"year > nineteen", 8, 8, `"nineteen" is not a number`},
+		{"price > 1.234", 9, 5, `"1.234" is not a number`},
+		{"finished < 2020-13-01", 12, 10, `"2020-13-01" is not a date, expected YYYY-MM-DD`},
+		{"автор = x", 1, 10, `unknown field "автор"`},
+		{"title = 'привет' and автор = x", 22, 10, `unknown field "автор"`},
+	}
+	for _, tt := range tests {
+		_, _, err := Compile(tt.input)
+		var ferr *Error
+		if !errors.As(err, &ferr) {
+			t.Errorf("Compile(%q) error = %v, want *Error", tt.input, err)
+			continue
+		}
+		if ferr.Column() != tt.column || ferr.Length != tt.length || !strings.HasPrefix(ferr.Msg, tt.msg) {
+			t.Errorf("Compile(%q) error at column %d, length %d: %q; want column %d, length %d: %q",
+				tt.input, ferr.Column(), ferr.Length, ferr.Msg, tt.column, tt.length, tt.msg)
+		}
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	_, _, err := Compile("title = 'ё' and autor ~ x")
+	want := "column 17: unknown field \"autor\" (valid: " + strings.Join(Fields(), ", ") + ")\n" +
+		"  title = 'ё' and autor ~ x\n" +
+		"                  ^^^^^"
+	if err == nil || err.Error() != want {
+		t.Errorf("error =\n%v\nwant\n%s", err, want)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+		expr []string
+	}{
+		{"", "id", []string{"id"}},
+		{"title,-year", "title,-year,id", []string{"title COLLATE UNICODE", "COALESCE(published_year, 0)", "id"}},
+		{" +Status , id, title", "status,id", []string{"COALESCE(status, '') COLLATE UNICODE", "id"}},
+		{"-finished", "-finished,id", []string{"read_date", "id"}},
+	}
+	for _, tt := range tests {
+		order, err := ParseSort(tt.spec)
+		if err != nil {
+			t.Errorf("ParseSort(%q): %v", tt.spec, err)
+			continue
+		}
+		var exprs []string
+		for _, k := range order {
+			exprs = append(exprs, k.Expr)
+		}
+		if order.String() != tt.want || !reflect.DeepEqual(exprs, tt.expr) {
+			t.Errorf("ParseSort(%q) = %s %q, want %s %q", tt.spec, order, exprs, tt.want, tt.expr)
+		}
+	}
+
+	if _, err := ParseSort("title,autor"); err == nil {
+		t.Error(`ParseSort("title,autor") succeeded, want an unknown field error`)
+	}
+}
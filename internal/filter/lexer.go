@@ -0,0 +1,166 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return i18n.T("end of expression")
+	case tokIdent:
+		return i18n.T("word")
+	case tokString:
+		return i18n.T("string")
+	case tokNumber:
+		return i18n.T("number")
+	case tokOp:
+		return i18n.T("operator")
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokAnd:
+		return `"and"`
+	case tokOr:
+		return `"or"`
+	case tokNot:
+		return `"not"`
+	}
+	return i18n.T("token")
+}
+
+type token struct {
+	kind tokenKind
+	text string // raw text as written in the expression
+	val  string // unquoted value for strings, text otherwise
+	pos  int    // byte offset in the expression
+}
+
+var operators = []string{"!=", "<=", ">=", "!~", "=", "<", ">", "~"}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", val: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", val: ")", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			tok, err := lexString(input, i, byte(r))
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i += len(tok.text)
+		case strings.ContainsRune("=!<>~", r):
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(input[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, newError(input, i, 1, "unexpected %q, did you mean \"!=\" or \"!~\"?", string(r))
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, val: op, pos: i})
+			i += len(op)
+		case isWordRune(r):
+			start := i
+			for i < len(input) {
+				r, size := utf8.DecodeRuneInString(input[i:])
+				if !isWordRune(r) {
+					break
+				}
+				i += size
+			}
+			word := input[start:i]
+			tok := token{kind: tokIdent, text: word, val: word, pos: start}
+			switch strings.ToLower(word) {
+			case "and":
+				tok.kind = tokAnd
+			case "or":
+				tok.kind = tokOr
+			case "not":
+				tok.kind = tokNot
+			default:
+				if isNumber(word) {
+					tok.kind = tokNumber
+				}
+			}
+			tokens = append(tokens, tok)
+		default:
+			return nil, newError(input, i, size, "unexpected character %q", string(r))
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(input)})
+	return tokens, nil
+}
+
+// lexString reads a quoted string starting at input[start]. A backslash
+// escapes the next character.
+func lexString(input string, start int, quote byte) (token, error) {
+	var sb strings.Builder
+	i := start + 1
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == '\\' && i+1 < len(input):
+			sb.WriteByte(input[i+1])
+			i += 2
+		case c == quote:
+			return token{kind: tokString, text: input[start : i+1], val: sb.String(), pos: start}, nil
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return token{}, newError(input, start, len(input)-start, "unterminated string")
+}
+
+// Word characters cover bare values such as en-GB, 2020-01-05 or 12.50.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+func isNumber(word string) bool {
+	digits := 0
+	for i, r := range word {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case r == '.' && i > 0:
+		case r == '-' && i == 0:
+		default:
+			return false
+		}
+	}
+	return digits > 0 && strings.Count(word, ".") <= 1
+}
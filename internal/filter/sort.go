@@ -0,0 +1,125 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+// SortKey is one column of an ORDER BY clause.
+type SortKey struct {
+	Field string
+	Expr  string // SQL expression, including any collation
+	Desc  bool
+}
+
+// Order is a complete, deterministic sort order: the book ID is always the
+// last key so that every row has a unique position, which keyset
+// pagination relies on.
+type Order []SortKey
+
+// ParseSort parses a sort specification such as "title,-year": a comma
+// separated list of fields, each optionally prefixed with "-" for
+// descending or "+" for ascending order. An empty specification sorts by ID.
+func ParseSort(spec string) (Order, error) {
+	var order Order
+	hasID := false
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, desc := strings.CutPrefix(part, "-")
+		if !desc {
+			name, _ = strings.CutPrefix(name, "+")
+		}
+		name = strings.ToLower(name)
+		f, ok := fields[name]
+		if !ok {
+			return nil, i18n.Errorf("unknown sort field %q (valid: %s)", name, strings.Join(Fields(), ", "))
+		}
+		order = append(order, SortKey{Field: name, Expr: f.sortExpr(), Desc: desc})
+		if name == "id" {
+			hasID = true
+			break // ID is unique, later keys would never be consulted
+		}
+	}
+	if !hasID {
+		order = append(order, SortKey{Field: "id", Expr: "id"})
+	}
+	return order, nil
+}
+
+func (f field) sortExpr() string {
+	column := f.column
+	if f.nullable {
+		if f.kind == textField {
+			column = "COALESCE(" + column + ", '')"
+		} else {
+			column = "COALESCE(" + column + ", 0)"
+		}
+	}
+	if f.kind == textField {
+		return column + " COLLATE UNICODE"
+	}
+	return column
+}
+
+// String returns the specification the order was parsed from, in canonical form.
+func (o Order) String() string {
+	parts := make([]string, len(o))
+	for i, k := range o {
+		parts[i] = k.Field
+		if k.Desc {
+			parts[i] = "-" + k.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Select returns the sort expressions as a select list.
+func (o Order) Select() string {
+	exprs := make([]string, len(o))
+	for i, k := range o {
+		exprs[i] = k.Expr
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// OrderBy returns the ORDER BY clause without the keywords.
+func (o Order) OrderBy() string {
+	parts := make([]string, len(o))
+	for i, k := range o {
+		parts[i] = k.Expr
+		if k.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// After returns a condition selecting the rows that sort after the row
+// whose sort values are given, for keyset pagination:
+//
+//	k1 > v1 OR (k1 = v1 AND k2 > v2) OR ...
+//
+// with "<" for descending keys.
+func (o Order) After(values []any) (string, []any) {
+	var terms []string
+	var args []any
+	for i, k := range o {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, o[j].Expr+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if k.Desc {
+			op = "<"
+		}
+		parts = append(parts, k.Expr+" "+op+" ?")
+		args = append(args, values[i])
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
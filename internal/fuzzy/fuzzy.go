@@ -0,0 +1,115 @@
+// Package fuzzy scores how well a typed query matches a title or a name,
+// tolerating typos: "hobit" matches "The Hobbit" and "Dostoyevsky" matches
+// "Dostoevsky".
+//
+// Query and text are compared word by word with the Damerau-Levenshtein
+// distance (optimal string alignment: insertions, deletions, substitutions
+// and transpositions of adjacent letters each count as one edit).
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MinScore is the lowest score that still counts as a match.
+const MinScore = 0.7
+
+// Distance returns the number of single letter edits that turn a into b.
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	// d[i][j] is the distance between s[:i] and t[:j]; three rows suffice.
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(t)]
+}
+
+// similarity compares two case folded words: 1 for equal words, falling
+// towards 0 with every edit. A query word that is a (possibly misspelled)
+// prefix of the text word scores a little lower than a whole word would.
+func similarity(query, word string) float64 {
+	q, w := []rune(query), []rune(word)
+	if len(q) == 0 || len(w) == 0 {
+		return 0
+	}
+	score := 1 - float64(Distance(query, word))/float64(max(len(q), len(w)))
+	if len(q) >= 3 && len(q) < len(w) {
+		prefix := 1 - float64(Distance(query, string(w[:len(q)])))/float64(len(q))
+		score = max(score, prefix*0.9)
+	}
+	return score
+}
+
+// A word is a run of letters and digits in a text, case folded, with its
+// byte offsets in the text.
+type word struct {
+	text       string
+	start, end int
+}
+
+func words(s string) []word {
+	var ws []word
+	start := -1
+	for i, r := range s {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && start < 0 {
+			start = i
+		}
+		if !inWord && start >= 0 {
+			ws = append(ws, word{strings.ToLower(s[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ws = append(ws, word{strings.ToLower(s[start:]), start, len(s)})
+	}
+	return ws
+}
+
+// Match scores text against query from 0 (nothing alike) to 1 (every query
+// word appears in text) and returns the byte ranges of the text words that
+// matched a query word with at least MinScore.
+func Match(query, text string) (score float64, hits [][2]int) {
+	qs, ts := words(query), words(text)
+	if len(qs) == 0 {
+		return 0, nil
+	}
+
+	matched := make([]bool, len(ts))
+	for _, q := range qs {
+		best, bestAt := 0.0, -1
+		for i, t := range ts {
+			if s := similarity(q.text, t.text); s > best {
+				best, bestAt = s, i
+			}
+		}
+		score += best
+		if best >= MinScore {
+			matched[bestAt] = true
+		}
+	}
+	for i, t := range ts {
+		if matched[i] {
+			hits = append(hits, [2]int{t.start, t.end})
+		}
+	}
+	return score / float64(len(qs)), hits
+}
@@ -0,0 +1,187 @@
+// Package i18n translates the messages of the command line and the TUI.
+//
+// Messages are identified by their English text, so code reads as before
+// with T, Sprintf or Errorf around its strings, and a message missing from
+// a catalog is shown in English. Long texts are translated paragraph by
+// paragraph with Text. Messages about a number of things take an English
+// singular and plural with N, and get as many forms as the language has.
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// catalog holds the translations of one language and its conventions.
+type catalog struct {
+	messages   map[string]string
+	plurals    map[string][]string // forms by the English plural
+	plural     func(n int) int     // index of the form for n
+	decimal    string
+	group      string // thousands separator
+	months     [12]string
+	monthsAbbr [12]string
+	dateFormat string // default --date-format
+}
+
+var english = &catalog{
+	plural: func(n int) int {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	},
+	decimal:    ".",
+	group:      ",",
+	dateFormat: "YYYY-MM-DD",
+}
+
+var catalogs = map[string]*catalog{"en": english, "ru": russian}
+
+// Languages lists the languages messages are available in.
+var Languages = []string{"en", "ru"}
+
+var current = english
+
+// Parse returns the language of a locale name such as "ru", "ru-RU" or
+// "ru_RU.UTF-8".
+func Parse(locale string) (string, error) {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; !ok {
+		return "", fmt.Errorf("unsupported language %q (valid: %s)", locale, strings.Join(Languages, ", "))
+	}
+	return lang, nil
+}
+
+// FromEnvironment returns the language of the locale set with LC_ALL,
+// LC_MESSAGES or LANG, or English.
+func FromEnvironment() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if lang, err := Parse(value); err == nil {
+				return lang
+			}
+			return "en" // e.g. C or POSIX
+		}
+	}
+	return "en"
+}
+
+// Set selects the language of messages; see Parse.
+func Set(lang string) error {
+	lang, err := Parse(lang)
+	if err != nil {
+		return err
+	}
+	current = catalogs[lang]
+	return nil
+}
+
+// T returns the translation of msg.
+func T(msg string) string {
+	if t, ok := current.messages[msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// TC returns the translation of msg in a context, for English words with
+// several meanings such as VALUE, a setting's or a book's.
+func TC(context, msg string) string {
+	if t, ok := current.messages[context+"\x04"+msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// Sprintf formats the translation of format.
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Errorf is fmt.Errorf with the translation of format.
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
+
+// N formats the form of a message for the number n, given by its English
+// singular and plural.
+func N(n int, one, other string, args ...any) string {
+	forms, ok := current.plurals[other]
+	if !ok {
+		forms = []string{one, other}
+		if current != english {
+			return fmt.Sprintf(english.form(n, forms), args...)
+		}
+	}
+	return fmt.Sprintf(current.form(n, forms), args...)
+}
+
+func (c *catalog) form(n int, forms []string) string {
+	i := c.plural(n)
+	if i >= len(forms) {
+		i = len(forms) - 1
+	}
+	return forms[i]
+}
+
+// Text translates a text of several paragraphs, separated by blank lines,
+// one paragraph at a time. Paragraphs without translation, such as
+// examples, are kept.
+func Text(text string) string {
+	paragraphs := strings.Split(text, "\n\n")
+	for i, p := range paragraphs {
+		paragraphs[i] = T(p)
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// Number formats v with prec decimal places, thousands grouped.
+func Number(v float64, prec int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', prec, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var sb strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		sb.WriteByte('-')
+	}
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteString(current.group)
+		}
+		sb.WriteRune(r)
+	}
+	if frac != "" {
+		sb.WriteString(current.decimal + frac)
+	}
+	return sb.String()
+}
+
+// DateFormat returns the usual date format of the language, in the
+// notation of --date-format.
+func DateFormat() string {
+	return current.dateFormat
+}
+
+// Date formats t with a time layout, naming months in the language.
+func Date(t time.Time, layout string) string {
+	s := t.Format(layout)
+	if current == english {
+		return s
+	}
+	month := t.Month() - 1
+	switch {
+	case strings.Contains(layout, "January"):
+		s = strings.Replace(s, t.Month().String(), current.months[month], 1)
+	case strings.Contains(layout, "Jan"):
+		s = strings.Replace(s, t.Month().String()[:3], current.monthsAbbr[month], 1)
+	}
+	return s
+}
@@ -0,0 +1,816 @@
+package i18n
+
+var russian = &catalog{
+	messages: ruMessages,
+	plurals:  ruPlurals,
+	// one: 1, 21, 101; few: 2-4, 22-24; many: 0, 5-20, 25-30
+	plural: func(n int) int {
+		if n < 0 {
+			n = -n
+		}
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		}
+		return 2
+	},
+	decimal: ",",
+	group:   "\u00a0", // no-break space
+	// Months are named in the genitive, as in dates: 5 марта 2024.
+	months: [12]string{"января", "февраля", "марта", "апреля", "мая", "июня",
+		"июля", "августа", "сентября", "октября", "ноября", "декабря"},
+	monthsAbbr: [12]string{"янв", "фев", "мар", "апр", "мая", "июн",
+		"июл", "авг", "сен", "окт", "ноя", "дек"},
+	dateFormat: "DD.MM.YYYY",
+}
+
+// ruPlurals holds the forms one, few and many by the English plural.
+var ruPlurals = map[string][]string{
+	"%d books":                                                 {"%d книга", "%d книги", "%d книг"},
+	"%d books deleted (IDs %s)":                                {"%d книга удалена (ID %s)", "%d книги удалены (ID %s)", "%d книг удалено (ID %s)"},
+	"%d books updated (IDs %s)":                                {"%d книга изменена (ID %s)", "%d книги изменены (ID %s)", "%d книг изменено (ID %s)"},
+	"%d books marked as read (IDs %s)":                         {"%d книга отмечена как прочитанная (ID %s)", "%d книги отмечены как прочитанные (ID %s)", "%d книг отмечено как прочитанные (ID %s)"},
+	"%d books merged (IDs %s)":                                 {"%d дубликат объединён (ID %s)", "%d дубликата объединены (ID %s)", "%d дубликатов объединено (ID %s)"},
+	"Books with IDs %s merged into book with ID %d":            {"Книга с ID %s присоединена к книге с ID %d", "Книги с ID %s присоединены к книге с ID %d", "Книги с ID %s присоединены к книге с ID %d"},
+	"this would delete %d books; add --yes to confirm":         {"будет удалена %d книга; добавьте --yes для подтверждения", "будут удалены %d книги; добавьте --yes для подтверждения", "будет удалено %d книг; добавьте --yes для подтверждения"},
+	"this would update %d books; add --yes to confirm":         {"будет изменена %d книга; добавьте --yes для подтверждения", "будут изменены %d книги; добавьте --yes для подтверждения", "будет изменено %d книг; добавьте --yes для подтверждения"},
+	"%d unread books weighed; repeat this pick with --seed %d": {"взвешена %d непрочитанная книга; повторить этот выбор: --seed %d", "взвешены %d непрочитанные книги; повторить этот выбор: --seed %d", "взвешено %d непрочитанных книг; повторить этот выбор: --seed %d"},
+	"%d of %d books priced":                                    {"С ценой %d из %d книги", "С ценой %d из %d книг", "С ценой %d из %d книг"},
+	"%d found":                                                 {"найдена %d книга", "найдены %d книги", "найдено %d книг"},
+	"no exact matches, %d similar":                             {"точных совпадений нет, %d похожая", "точных совпадений нет, %d похожие", "точных совпадений нет, %d похожих"},
+}
+
+var ruMessages = map[string]string{
+	// Commands and flags of the root command and the help cobra adds
+	"A tool to storage book library":  "Программа для учёта домашней библиотеки",
+	"A tool to storage book library.": "Программа для учёта домашней библиотеки.",
+	`Exit codes: 0 success, 1 failure of the database or the system, 2 wrong
+usage, 3 not found, 4 invalid data, 5 conflict with stored data. With
+--output json, jsonl or yaml, errors are written to stderr in that format.`: `Коды выхода: 0 — успех, 1 — сбой базы данных или системы, 2 — неверный
+вызов, 3 — не найдено, 4 — недопустимые данные, 5 — конфликт с
+сохранёнными данными. С --output json, jsonl или yaml ошибки пишутся в
+stderr в этом формате.`,
+	"Configuration file (default $XDG_CONFIG_HOME/book/config.toml)": "Файл настроек (по умолчанию $XDG_CONFIG_HOME/book/config.toml)",
+	"SQLite database file": "Файл базы данных SQLite",
+	"Format of dates in table output, e.g. DD.MM.YYYY or D MMM YYYY":                   "Формат дат в табличном выводе, например DD.MM.YYYY или D MMM YYYY",
+	"Language of messages: en, ru (default from $LC_ALL, $LC_MESSAGES or $LANG)":       "Язык сообщений: en, ru (по умолчанию из $LC_ALL, $LC_MESSAGES или $LANG)",
+	"Output format: table, json, jsonl, csv, tsv, yaml":                                "Формат вывода: table, json, jsonl, csv, tsv, yaml",
+	"Go text/template applied to every record, e.g. '{{.Title}} ({{.PublishedYear}})'": "Шаблон Go text/template для каждой записи, например '{{.Title}} ({{.PublishedYear}})'",
+	"help for %s":            "справка по %s",
+	"Help about any command": "Справка по любой команде",
+	`Help provides help for any command in the application.
+Simply type book help [path to command] for full details.`: `Выводит справку по любой команде программы.
+Наберите book help [путь к команде], чтобы узнать подробности.`,
+	"Generate the autocompletion script for the specified shell": "Создать скрипт автодополнения для указанной оболочки",
+	`Generate the autocompletion script for book for the specified shell.
+See each sub-command's help for details on how to use the generated script.
+`: `Создаёт скрипт автодополнения book для указанной оболочки.
+Как подключить скрипт, описано в справке каждой подкоманды.
+`,
+	"Generate the autocompletion script for bash":            "Создать скрипт автодополнения для bash",
+	"Generate the autocompletion script for zsh":             "Создать скрипт автодополнения для zsh",
+	"Generate the autocompletion script for fish":            "Создать скрипт автодополнения для fish",
+	"Generate the autocompletion script for powershell":      "Создать скрипт автодополнения для powershell",
+	"Generate the autocompletion script for the bash shell.": "Создаёт скрипт автодополнения для оболочки bash.",
+	"Generate the autocompletion script for the zsh shell.":  "Создаёт скрипт автодополнения для оболочки zsh.",
+	"Generate the autocompletion script for the fish shell.": "Создаёт скрипт автодополнения для оболочки fish.",
+	"Generate the autocompletion script for powershell.":     "Создаёт скрипт автодополнения для powershell.",
+	`This script depends on the 'bash-completion' package.
+If it is not installed already, you can install it via your OS's package manager.`: `Скрипту нужен пакет 'bash-completion'.
+Если он ещё не установлен, его можно установить менеджером пакетов системы.`,
+	"To load completions in your current shell session:":       "Чтобы включить автодополнение в текущем сеансе оболочки:",
+	"To load completions for every new session, execute once:": "Чтобы включить автодополнение во всех новых сеансах, выполните один раз:",
+	`You will need to start a new shell for this setup to take effect.
+`: `Настройка вступит в силу в новом сеансе оболочки.
+`,
+	`To load completions for every new session, add the output of the above command
+to your powershell profile.
+`: `Чтобы включить автодополнение во всех новых сеансах, добавьте вывод этой
+команды в свой профиль powershell.
+`,
+	`If shell completion is not already enabled in your environment you will need
+to enable it.  You can execute the following once:`: `Если автодополнение в вашей оболочке ещё не включено, его нужно включить.
+Для этого достаточно один раз выполнить:`,
+	"disable completion descriptions": "не показывать описания вариантов",
+	"Usage:":                          "Использование:",
+	"Aliases:":                        "Псевдонимы:",
+	"Examples:":                       "Примеры:",
+	"Available Commands:":             "Команды:",
+	"Additional Commands:":            "Другие команды:",
+	"Flags:":                          "Флаги:",
+	"Global Flags:":                   "Общие флаги:",
+	"Additional help topics:":         "Другие разделы справки:",
+	"Use \"{{.CommandPath}} [command] --help\" for more information about a command.": "Подробнее о команде: \"{{.CommandPath}} [команда] --help\".",
+	"{{.CommandPath}} [command]": "{{.CommandPath}} [команда]",
+
+	// cmd/errors.go, cmd/output.go, cmd/prompt.go
+	"Error: %v":                  "Ошибка: %v",
+	"Run '%s --help' for usage.": "Справка: '%s --help'.",
+	"invalid date format %q: expected e.g. DD.MM.YYYY": "недопустимый формат даты %q: ожидается, например, DD.MM.YYYY",
+	"invalid output format: %v":                        "недопустимый формат вывода: %v",
+	"failed to write output: %w":                       "не удалось вывести результат: %w",
+	"no answer given":                                  "ответа нет",
+	"Choose 1-%d: ":                                    "Выберите 1-%d: ",
+	"no choice made":                                   "ничего не выбрано",
+	"failed to connect to database: %w":                "не удалось подключиться к базе данных: %w",
+
+	// cmd/add.go, cmd/book_flags.go
+	"Add a new book":  "Добавить книгу",
+	"Add a new book.": "Добавляет книгу.",
+	`Title, author and year are required. When one is missing and the command
+runs in a terminal, it asks for the missing fields, and for the year and
+status unless given. Typing the start of an author's name, or the name
+with a typo, offers the matching authors of the library.`: `Название, автор и год обязательны. Если чего-то не хватает и команда
+запущена в терминале, она спрашивает недостающие поля, а также год и
+статус, если они не заданы. По началу имени автора или имени с опечаткой
+предлагаются подходящие авторы из библиотеки.`,
+	`A book that is likely already in the library, having the same ISBN or a
+similar title and author, is added with a warning, or refused with
+--strict.`: `Книга, которая, вероятно, уже есть в библиотеке (тот же ISBN или похожие
+название и автор), добавляется с предупреждением, а с --strict не
+добавляется.`,
+	"Book title":                "Название книги",
+	"Book author":               "Автор книги",
+	"Book status (read/unread)": "Статус книги (read/unread)",
+	"Published year":            "Год издания",
+	"Refuse to add a likely duplicate of a book in the library":          "Не добавлять вероятный дубликат книги из библиотеки",
+	"Publisher of this edition":                                          "Издательство этого издания",
+	"Language of this edition (BCP-47, e.g. ru, en-GB)":                  "Язык этого издания (BCP-47, например ru, en-GB)",
+	"Language the book was translated from (BCP-47)":                     "Язык, с которого переведена книга (BCP-47)",
+	"Title of the original work":                                         "Название оригинала",
+	"Where the copy is kept":                                             "Где хранится экземпляр",
+	"Format of the copy (hardcover, paperback, ebook, audiobook, other)": "Формат экземпляра (hardcover, paperback, ebook, audiobook, other)",
+	"Purchase date (YYYY-MM-DD)":                                         "Дата покупки (ГГГГ-ММ-ДД)",
+	"Purchase price, e.g. 12.50":                                         "Цена покупки, например 12.50",
+	"Currency of price and value (ISO 4217, e.g. EUR)":                   "Валюта цены и стоимости (ISO 4217, например EUR)",
+	"Current estimated value, e.g. 30":                                   "Текущая оценочная стоимость, например 30",
+	"Free-form notes":                                                    "Заметки в свободной форме",
+	"Number of pages":                                                    "Число страниц",
+	"Date the book was last read (YYYY-MM-DD)":                           "Дата, когда книга прочитана последний раз (ГГГГ-ММ-ДД)",
+	"ISBN-10 or ISBN-13, hyphens allowed":                                "ISBN-10 или ISBN-13, можно с дефисами",
+	"failed to add book: %w":                                             "не удалось добавить книгу: %w",
+	"missing %s":                                                         "не указано: %s",
+	"failed to get authors: %w":                                          "не удалось получить авторов: %w",
+	"failed to look for duplicates: %w":                                  "не удалось проверить дубликаты: %w",
+	"likely a duplicate of %s":                                           "вероятно, дубликат: %s",
+	"Warning: likely a duplicate of %s; see book dedupe":                 "Внимание: вероятно, дубликат: %s; см. book dedupe",
+	"Book added successfully!":                                           "Книга добавлена!",
+	"title (--title)":                                                    "название (--title)",
+	"author (--author)":                                                  "автор (--author)",
+	"year (--year)":                                                      "год (--year)",
+	"Title":                                                              "Название",
+	"Author":                                                             "Автор",
+	"Year":                                                               "Год",
+	"%q is not a number of pages":                                        "%q — не число страниц",
+	"%q is not a year":                                                   "%q — не год",
+	"Status (%s)":                                                        "Статус (%s)",
+	"%s (new author)":                                                    "%s (новый автор)",
+	"Did you mean:":                                                      "Возможно, вы имели в виду:",
+
+	// cmd/alias.go
+	`Quote arguments that go into a filter expression, as '$1' above: within
+quotes, the quotes and backslashes of an argument are escaped, so an
+argument such as O'Brien is taken as text and cannot change the
+expression.`: `Аргументы, которые попадают в выражение фильтра, заключайте в кавычки,
+как '$1' выше: внутри кавычек кавычки и обратные косые черты аргумента
+экранируются, так что аргумент вроде O'Brien берётся как текст и не может
+изменить выражение.`,
+	"Warning: alias %s skipped: %v": "Внимание: псевдоним %s пропущен: %v",
+	"there is a command named %s":   "есть команда с именем %s",
+	"Alias for: %s":                 "Псевдоним для: %s",
+	"Alias for: book %s":            "Псевдоним для: book %s",
+	"alias %s: %w":                  "псевдоним %s: %w",
+	"alias %s: aliases expand into each other more than %d times": "псевдоним %s: псевдонимы раскрываются друг в друга больше %d раз",
+	"needs an argument":           "нужен аргумент",
+	"needs at least %d arguments": "нужно не меньше %d аргументов",
+	"runs no command":             "не запускает никакой команды",
+	`Aliases are commands of your own, defined in the [alias] table of the
+configuration file (see book config) as the command line they run:`: `Псевдонимы — это ваши собственные команды, заданные в таблице [alias]
+файла настроек (см. book config) командной строкой, которую они
+запускают:`,
+	`$1 to $9 stand for the arguments of the alias and $@ for all of them; an
+alias without them gets its arguments appended, so "book unread -n 5"
+runs "book list --where 'status = unread' --sort title -n 5". $$ is a
+literal $.`: `$1–$9 обозначают аргументы псевдонима, а $@ — все аргументы сразу;
+псевдониму без них аргументы добавляются в конец, так что "book unread
+-n 5" запускает "book list --where 'status = unread' --sort title -n 5".
+$$ обозначает сам знак $.`,
+
+	// cmd/author.go
+	"Manage author profiles and aliases":              "Управлять профилями и псевдонимами авторов",
+	"List authors by sort name":                       "Перечислить авторов по имени для сортировки",
+	"Show an author profile":                          "Показать профиль автора",
+	"Update an author profile":                        "Изменить профиль автора",
+	"Add pen names or spelling variants of an author": "Добавить псевдонимы или варианты написания имени автора",
+	"Merge author b into author a":                    "Объединить автора b с автором a",
+	"Merge author b into author a.":                   "Объединяет автора b с автором a.",
+	`All books and aliases of b are moved to a, missing profile fields of a are
+filled in from b, and b is deleted. Authors are given by ID or any name.`: `Все книги и псевдонимы b переходят к a, недостающие поля профиля a
+заполняются из b, а b удаляется. Авторы задаются ID или любым из имён.`,
+	"Canonical display name":                           "Основное отображаемое имя",
+	"Sort name, e.g. \"Tolkien, J. R. R.\"":            "Имя для сортировки, например \"Толкин, Дж. Р. Р.\"",
+	"Birth year":                                       "Год рождения",
+	"Death year":                                       "Год смерти",
+	"Country":                                          "Страна",
+	"failed to find authors: %w":                       "не удалось найти авторов: %w",
+	"failed to find author: %w":                        "не удалось найти автора: %w",
+	"failed to update author: %w":                      "не удалось изменить автора: %w",
+	"Author with ID %d updated successfully":           "Автор с ID %d изменён",
+	"failed to add alias: %w":                          "не удалось добавить псевдоним: %w",
+	"Aliases for author with ID %d added successfully": "Псевдонимы автора с ID %d добавлены",
+	"failed to merge authors: %w":                      "не удалось объединить авторов: %w",
+	"Author %q merged into %q":                         "Автор %q объединён с %q",
+	"No authors found":                                 "Авторы не найдены",
+	"SORT NAME":                                        "ИМЯ ДЛЯ СОРТИРОВКИ",
+	"ALIASES":                                          "ПСЕВДОНИМЫ",
+	"Name":                                             "Имя",
+	"Sort name":                                        "Имя для сортировки",
+	"Aliases":                                          "Псевдонимы",
+	"Lived":                                            "Годы жизни",
+
+	// cmd/batch.go
+	"Run commands from a file in one transaction":                         "Выполнить команды из файла в одной транзакции",
+	"Run commands from a file, or from stdin with -, in one transaction.": "Выполняет команды из файла или, с -, из stdin в одной транзакции.",
+	`Every line holds one command as it would follow "book" on the command
+line, quoted like in a shell; the leading "book" may be kept. Empty lines
+and lines starting with # are skipped:`: `Каждая строка содержит одну команду в том виде, в каком она шла бы после
+"book" в командной строке, с кавычками как в оболочке; начальное "book"
+можно оставить. Пустые строки и строки, начинающиеся с #, пропускаются:`,
+	`If any line fails, the batch stops with the line number and none of its
+changes are kept. Flags given to batch itself, such as --output, apply to
+every line. Commands never ask questions in a batch.`: `Если какая-то строка не выполнилась, пакет останавливается с номером
+строки и ни одно из его изменений не сохраняется. Флаги самой команды
+batch, такие как --output, действуют на каждую строку. В пакете команды
+никогда ничего не спрашивают.`,
+	"failed to read batch: %w":          "не удалось прочитать пакет: %w",
+	"line %d: %w; no changes were made": "строка %d: %w; изменения не внесены",
+	"failed to commit batch: %w":        "не удалось сохранить пакет: %w",
+	"line %d: %v":                       "строка %d: %v",
+	"line %d: %s cannot run in a batch": "строка %d: %s нельзя запускать в пакете",
+	"unterminated %c quote":             "не закрыта кавычка %c",
+	"line ends with a backslash":        "строка кончается обратной косой чертой",
+
+	// cmd/bulk.go, cmd/delete.go, cmd/update.go
+	`Books are given by ID, by ranges of IDs such as 10-25, by - to read IDs
+and ranges from stdin, or by a filter expression with --where (see book
+list --help). All books are changed in one transaction: if one fails,
+none is changed. Changing more books than --confirm-above requires --yes.`: `Книги задаются ID, диапазонами ID вроде 10-25, знаком -, чтобы прочитать
+ID и диапазоны из stdin, или выражением фильтра в --where (см. book list
+--help). Все книги меняются в одной транзакции: если не удалось изменить
+одну, не меняется ни одна. Чтобы изменить больше книг, чем
+--confirm-above, нужен --yes.`,
+	`Books are given by ID, by ranges of IDs such as 10-25, by - to read IDs
+and ranges from stdin, or by a filter expression with --where (see book
+list --help). All books are changed in one transaction: if one fails,
+none is changed. Changing more books than --confirm-above requires --yes. A single book may also be given by title or author, as
+in show.`: `Книги задаются ID, диапазонами ID вроде 10-25, знаком -, чтобы прочитать
+ID и диапазоны из stdin, или выражением фильтра в --where (см. book list
+--help). Все книги меняются в одной транзакции: если не удалось изменить
+одну, не меняется ни одна. Чтобы изменить больше книг, чем
+--confirm-above, нужен --yes. Одну книгу можно задать и названием или
+автором, как в show.`,
+	"Act on the books matching a filter expression":             "Обработать книги, подходящие под выражение фильтра",
+	"Confirm changing more books than --confirm-above":          "Подтвердить изменение большего числа книг, чем --confirm-above",
+	"Number of books above which --yes is required":             "Число книг, больше которого нужен --yes",
+	"give either books or --where, not both":                    "задайте либо книги, либо --where, но не то и другое",
+	"no books given":                                            "книги не заданы",
+	"failed to read IDs from stdin: %v":                         "не удалось прочитать ID из stdin: %v",
+	"invalid ID %q: expected a number or a range such as 10-25": "недопустимый ID %q: ожидается число или диапазон вроде 10-25",
+	"invalid range %q: %d is greater than %d":                   "недопустимый диапазон %q: %d больше %d",
+	"No books matched":                                          "Подходящих книг нет",
+	"Delete a book by ID":                                       "Удалить книгу по ID",
+	"Delete books with their relations and quotes.":             "Удаляет книги вместе с их связями и цитатами.",
+	"failed to find book: %w":                                   "не удалось найти книгу: %w",
+	"failed to delete books: %w":                                "не удалось удалить книги: %w",
+	"failed to delete book: %w":                                 "не удалось удалить книгу: %w",
+	"Book with ID %d deleted successfully":                      "Книга с ID %d удалена",
+	"Update status a book by ID":                                "Изменить статус книги по ID",
+	"Update books.":                                             "Изменяет книги.",
+	`Without field flags the books are marked as read. With field flags only
+the given fields are changed.`: `Без флагов полей книги отмечаются как прочитанные. С флагами полей
+меняются только заданные поля.`,
+	"failed to update books: %w":                 "не удалось изменить книги: %w",
+	"failed to update book: %w":                  "не удалось изменить книгу: %w",
+	"Status book with ID %d update successfully": "Статус книги с ID %d изменён",
+	"Book with ID %d updated successfully":       "Книга с ID %d изменена",
+
+	// cmd/completion.go, cmd/config.go
+	"Show and change the settings in the configuration file":  "Показать и изменить настройки в файле настроек",
+	"Show and change the settings in the configuration file.": "Показывает и изменяет настройки в файле настроек.",
+	`Some flags can be given a default of your own: the output and database of
+every command, the theme of the TUI and the usual choices of a few
+commands, all listed by book config list. A setting is named after the
+command and the flag, e.g. list.sort for "book list --sort" and
+add.status for "book add --status"; flags of every command are named
+without a command. In the configuration file the command is a TOML table:`: `Некоторым флагам можно задать своё значение по умолчанию: формату вывода
+и базе данных всех команд, теме TUI и обычным настройкам нескольких
+команд — все они перечислены в book config list. Настройка называется по
+команде и флагу, например list.sort для "book list --sort" и add.status
+для "book add --status"; флаги всех команд называются без команды. В
+файле настроек команда — это таблица TOML:`,
+	`Flags that confirm an action, such as --yes, or that say which books a
+command changes have no setting, so that no setting can skip a question
+or change every book.`: `У флагов, подтверждающих действие, таких как --yes, и флагов, выбирающих
+книги, которые меняет команда, настроек нет, чтобы никакая настройка не
+могла пропустить вопрос или изменить все книги.`,
+	`The file is $XDG_CONFIG_HOME/book/config.toml (usually
+~/.config/book/config.toml), or the one given with --config or
+$BOOK_CONFIG. Settings can also be given as environment variables named
+BOOK_ and the setting in capitals with _ for . and -, e.g. BOOK_LIST_SORT.
+A flag on the command line wins over the environment, which wins over the
+configuration file, which wins over the built-in default.`: `Файл настроек — $XDG_CONFIG_HOME/book/config.toml (обычно
+~/.config/book/config.toml) или заданный в --config или $BOOK_CONFIG.
+Настройки можно задать и переменными окружения с именем из BOOK_ и
+настройки заглавными буквами, где . и - заменены на _, например
+BOOK_LIST_SORT. Флаг в командной строке важнее окружения, окружение
+важнее файла настроек, а файл важнее встроенного значения.`,
+	`Aliases are set like settings, e.g. book config set alias.unread "list
+--where 'status = unread'".`: `Псевдонимы задаются как настройки, например book config set
+alias.unread "list --where 'status = unread'".`,
+	"Print the value of a setting":                                 "Вывести значение настройки",
+	"Change a setting in the configuration file":                   "Изменить настройку в файле настроек",
+	"List all settings with their values and where they come from": "Перечислить все настройки со значениями и их источниками",
+	"no %s in %s":                               "%s нет в %s",
+	"failed to read setting: %w":                "не удалось прочитать настройку: %w",
+	"invalid alias %s: %v":                      "недопустимый псевдоним %s: %v",
+	"failed to save configuration: %w":          "не удалось сохранить настройки: %w",
+	"%s set to %s in %s":                        "%s = %s сохранено в %s",
+	"failed to set alias: %w":                   "не удалось задать псевдоним: %w",
+	"failed to change setting: %w":              "не удалось изменить настройку: %w",
+	"invalid value for %s: %v":                  "недопустимое значение %s: %v",
+	"invalid %s from %s: %v":                    "недопустимое значение %s из %s: %v",
+	"invalid configuration: %w":                 "ошибка в настройках: %v",
+	"no configuration directory: %v":            "нет каталога настроек: %v",
+	"unknown setting %q (see book config list)": "неизвестная настройка %q (см. book config list)",
+	"%q is not true or false":                   "%q — не true и не false",
+	"%q is not a whole number":                  "%q — не целое число",
+	"SETTING":                                   "НАСТРОЙКА",
+	"VALUE":                                     "ЗНАЧЕНИЕ",
+	"SOURCE":                                    "ИСТОЧНИК",
+	"env":                                       "окружение",
+	"config":                                    "файл",
+	"default":                                   "по умолчанию",
+	"config (unknown setting)":                  "файл (неизвестная настройка)",
+	"config (alias)":                            "файл (псевдоним)",
+
+	// cmd/cover.go
+	"Manage book cover images":                        "Управлять обложками книг",
+	"Attach a cover image (png, jpeg, gif) to a book": "Прикрепить к книге изображение обложки (png, jpeg, gif)",
+	"Copy the cover image of a book to a file":        "Скопировать обложку книги в файл",
+	"Copy the cover image of a book to a file.":       "Копирует обложку книги в файл.",
+	`Without a file name the cover is written to cover-<id>.<ext> in the
+current directory.`: `Без имени файла обложка записывается в cover-<id>.<ext> в текущем
+каталоге.`,
+	"failed to store cover: %w":                  "не удалось сохранить обложку: %w",
+	"failed to set cover: %w":                    "не удалось прикрепить обложку: %w",
+	"Cover for book with ID %d set successfully": "Обложка книги с ID %d прикреплена",
+	"book with ID %d has no cover":               "у книги с ID %d нет обложки",
+	"failed to export cover: %w":                 "не удалось скопировать обложку: %w",
+	"Cover for book with ID %d exported to %s":   "Обложка книги с ID %d скопирована в %s",
+
+	// cmd/dedupe.go
+	"Find books entered more than once and merge them":  "Найти книги, внесённые несколько раз, и объединить их",
+	"Find books entered more than once and merge them.": "Находит книги, внесённые несколько раз, и объединяет их.",
+	`Without arguments, lists the groups of likely duplicates: books with the
+same ISBN (ISBN-10 and ISBN-13 compare equal), or without ISBN and with a
+similar title and author, spelling variants, typos and either script
+included. Editions with different ISBNs or languages are not duplicates.`: `Без аргументов перечисляет группы вероятных дубликатов: книги с одним
+ISBN (ISBN-10 и ISBN-13 считаются равными) или без ISBN и с похожими
+названием и автором, включая варианты написания, опечатки и запись
+латиницей или кириллицей. Издания с разными ISBN или языками — не
+дубликаты.`,
+	`With --merge, asks for each group which book to keep and merges the others
+into it. Given books, merges the duplicates into the first one.`: `С --merge для каждой группы спрашивает, какую книгу оставить, и
+присоединяет к ней остальные. Если книги заданы, присоединяет дубликаты к
+первой из них.`,
+	`Merging keeps the fields of the kept book and fills in those it lacks from
+the duplicates. Notes are joined, quotes and relations move over, a book
+read as any of the copies is read, and the duplicates are deleted. Books
+are given by ID, or by title or author as in show.`: `При объединении поля оставляемой книги сохраняются, а недостающие берутся
+из дубликатов. Заметки соединяются, цитаты и связи переносятся, книга,
+прочитанная в любом из экземпляров, считается прочитанной, а дубликаты
+удаляются. Книги задаются ID, названием или автором, как в show.`,
+	"Ask which book of each group to keep and merge the others into it": "Спросить, какую книгу каждой группы оставить, и присоединить к ней остальные",
+	"give the book to keep and at least one duplicate":                  "задайте оставляемую книгу и хотя бы один дубликат",
+	"--merge takes no books":                                            "--merge не принимает книги",
+	"--merge needs a terminal; give the books to merge instead":         "--merge нужен терминал; задайте книги для объединения",
+	"failed to merge books: %w":                                         "не удалось объединить книги: %w",
+	"failed to find duplicates: %w":                                     "не удалось найти дубликаты: %w",
+	"Skip, these are different books":                                   "Пропустить, это разные книги",
+	"Group %d of %d (%s), keep:":                                        "Группа %d из %d (%s), оставить:",
+	"No books merged":                                                   "Книги не объединены",
+	"%s by %s (%d), ID %d":                                              "%s — %s (%d), ID %d",
+	"No duplicates found":                                               "Дубликаты не найдены",
+	"Group %d:":                                                         "Группа %d:",
+	"- ID: %d, Title: %s, Author: %s, Year: %d, ISBN: %s (%s)":          "- ID: %d, название: %s, автор: %s, год: %d, ISBN: %s (%s)",
+	"Merge them with: book dedupe --merge, or book dedupe <keep> <duplicate>...": "Объединить: book dedupe --merge или book dedupe <оставить> <дубликат>...",
+	"same ISBN":                "тот же ISBN",
+	"similar title and author": "похожие название и автор",
+
+	// cmd/edit.go
+	"Edit a book in your text editor":  "Изменить книгу в текстовом редакторе",
+	"Edit a book in your text editor.": "Открывает книгу в текстовом редакторе.",
+	`The book is written to a temporary YAML file and opened in $VISUAL or
+$EDITOR (vi if neither is set). After the editor exits, the changed
+fields are checked and listed, and you are asked before they are stored.
+When a field is invalid, the editor opens again with the problem noted
+above the field. Fields removed from the file are left unchanged; saving
+an empty file cancels the edit.`: `Книга записывается во временный файл YAML и открывается в $VISUAL или
+$EDITOR (vi, если не задан ни один). После выхода из редактора изменённые
+поля проверяются и выводятся, и перед сохранением задаётся вопрос. Если
+поле недопустимо, редактор открывается снова с описанием ошибки над
+полем. Удалённые из файла поля не меняются; сохранение пустого файла
+отменяет правку.`,
+	"The book is given by ID, or by title or author as in show.": "Книга задаётся ID, названием или автором, как в show.",
+	"edit needs a terminal; use update with field flags instead": "edit нужен терминал; используйте update с флагами полей",
+	"failed to edit book: %w":                                    "не удалось изменить книгу: %w",
+	"Edit cancelled":                                             "Правка отменена",
+	"No changes made":                                            "Изменений нет",
+	"Store these changes?":                                       "Сохранить эти изменения?",
+	"Yes":                                                        "Да",
+	"Edit again":                                                 "Изменить ещё раз",
+	"Discard them":                                               "Отбросить их",
+	"is not a field of books":                                    "не поле книги",
+	"must be a single value":                                     "должно быть одним значением",
+	"expected one field per line, such as title: The Hobbit": "ожидается одно поле на строку, например title: Хоббит",
+	"ERROR: ":              "ОШИБКА: ",
+	"editor %s failed: %v": "ошибка редактора %s: %v",
+	"(empty)":              "(пусто)",
+	`Book %d. Change the fields below, then save and quit the editor.
+Removed fields are left unchanged; an empty file cancels the edit.`: `Книга %d. Измените поля ниже, затем сохраните файл и закройте редактор.
+Удалённые поля не меняются; пустой файл отменяет правку.`,
+
+	// cmd/find-by-status.go, cmd/find_book.go, cmd/interactive.go
+	"Find books by status (read/unread)":                                         "Найти книги по статусу (read/unread)",
+	"Find books by status. Shorthand for: book list --where 'status = <status>'": "Находит книги по статусу. Сокращение для: book list --where 'status = <статус>'",
+	"Filter by status (read/unread)":                                             "Статус для отбора (read/unread)",
+	"no book matches %q":                                                         "нет книг, подходящих под %q",
+	`%q matches several books, give an ID instead:
+  %s`: `под %q подходит несколько книг, задайте ID:
+  %s`,
+	"Several books match %q:":        "Под %q подходит несколько книг:",
+	"Run TUI mode of application":    "Запустить программу в текстовом интерфейсе",
+	"Color theme: dark, light, mono": "Цветовая тема: dark, light, mono",
+	"invalid theme: %v":              "недопустимая тема: %v",
+
+	// cmd/list.go, cmd/search.go, cmd/show.go
+	"Output the list of book": "Вывести список книг",
+	"Output the list of books, optionally filtered, sorted and paginated.": `Выводит список книг, при желании отфильтрованный, отсортированный и
+разбитый на страницы.`,
+	`Filter expressions compare fields with =, !=, <, <=, >, >=, ~ (contains,
+case-insensitive) and !~, combined with and, or, not and parentheses:`: `Выражения фильтра сравнивают поля с помощью =, !=, <, <=, >, >=, ~
+(содержит, без учёта регистра) и !~ и объединяются через and, or, not и
+скобки:`,
+	"Sorting takes a comma separated list of fields, \"-\" sorts descending:": "Сортировка принимает список полей через запятую, \"-\" сортирует по убыванию:",
+	`Every paginated listing prints a cursor for the next page; --after <cursor>
+continues from there and stays fast however deep the page is.`: `Каждый постраничный вывод печатает курсор следующей страницы;
+--after <курсор> продолжает с этого места и работает быстро на любой
+глубине.`,
+	"Fields: author, currency, finished, format, id, isbn, language, location, notes, original_language, original_title, pages, price, publisher, purchased, status, title, value, year": "Поля: author, currency, finished, format, id, isbn, language, location, notes, original_language, original_title, pages, price, publisher, purchased, status, title, value, year",
+	"Filter expression, e.g. 'status = unread and year < 1900'": "Выражение фильтра, например 'status = unread and year < 1900'",
+	"Sort fields, e.g. title,-year (default: id)":               "Поля сортировки, например title,-year (по умолчанию: id)",
+	"Maximum number of books to show (0: all)":                  "Наибольшее число выводимых книг (0: все)",
+	"Number of books to skip":                                   "Сколько книг пропустить",
+	"Page number, counted in --limit sized pages":               "Номер страницы размером --limit",
+	"Continue after the cursor printed with the previous page":  "Продолжить после курсора, выведенного с предыдущей страницей",
+	"invalid page %d: pages start at 1":                         "недопустимая страница %d: страницы нумеруются с 1",
+	"--page requires --limit":                                   "--page требует --limit",
+	"invalid offset %d":                                         "недопустимое смещение %d",
+	"failed to find books: %w":                                  "не удалось найти книги: %w",
+	"No books found":                                            "Книги не найдены",
+	"- ID: %d, Title: %s, Author: %s, Year: %d, Status: %s":     "- ID: %d, название: %s, автор: %s, год: %d, статус: %s",
+	"Page %d of %d (books %d-%d of %d)":                         "Страница %d из %d (книги %d-%d из %d)",
+	"Next page: --after %s":                                     "Следующая страница: --after %s",
+	"Search titles, authors, notes and quotes":                  "Искать в названиях, авторах, заметках и цитатах",
+	`Search titles, original titles, authors (with their aliases), notes and
+quotes. Results are ranked by relevance (BM25), best first.`: `Ищет в названиях, названиях оригиналов, авторах (с их псевдонимами),
+заметках и цитатах. Результаты упорядочены по релевантности (BM25),
+лучшие первыми.`,
+	`  book search tolkien ring          books matching both words
+  book search 'tolk*'               words starting with "tolk"
+  book search '"war and peace"'     the exact phrase
+  book search 'hobbit OR silmarillion'
+  book search 'ring NOT author:tolkien'`: `  book search tolkien ring          книги, где есть оба слова
+  book search 'tolk*'               слова, начинающиеся с "tolk"
+  book search '"war and peace"'     точная фраза
+  book search 'hobbit OR silmarillion'
+  book search 'ring NOT author:tolkien'`,
+	`Columns usable with column:word are title, original_title, author, notes
+and quotes. In structured output the matched words of a snippet are
+enclosed in [brackets].`: `В запросах вида столбец:слово можно использовать title, original_title,
+author, notes и quotes. В структурированном выводе найденные слова
+фрагмента заключены в [скобки].`,
+	`With --fuzzy, titles and authors are matched despite typos instead:
+"dostoyevsky" finds "Dostoevsky" and "tolkein" finds "Tolkien". Query
+syntax does not apply then; every word is compared on its own.`: `С --fuzzy названия и авторы находятся несмотря на опечатки: "dostoyevsky"
+находит "Dostoevsky", а "толкин" — "Толкин". Синтаксис запросов тогда не
+действует; каждое слово сравнивается само по себе.`,
+	`Searching needs a build with FTS5, which make build and make install
+produce (go build -tags sqlite_fts5). Other builds only search with --fuzzy.`: `Для поиска нужна сборка с FTS5, которую дают make build и make install
+(go build -tags sqlite_fts5). Другие сборки ищут только с --fuzzy.`,
+	"Tolerate typos in titles and authors": "Допускать опечатки в названиях и авторах",
+	"Maximum number of results":            "Наибольшее число результатов",
+	"failed to search books: %w":           "не удалось выполнить поиск: %w",
+	"this build has no FTS5 support; install book with make install, or search with --fuzzy": "эта сборка не поддерживает FTS5; установите book через make install или ищите с --fuzzy",
+	"Show all details of a book":  "Показать все сведения о книге",
+	"Show all details of a book.": "Показывает все сведения о книге.",
+	`The book is given by ID, or by title or author as remembered: "book show
+hobit" finds "The Hobbit". When several books match, you are asked which
+one you mean.`: `Книга задаётся ID или названием либо автором, как запомнилось: "book show
+хобит" находит "Хоббит". Если подходит несколько книг, программа
+спросит, какая имеется в виду.`,
+	"failed to find related books: %w": "не удалось найти связанные книги: %w",
+	"failed to find quotes: %w":        "не удалось найти цитаты: %w",
+	"Status":                           "Статус",
+	"Publisher":                        "Издательство",
+	"Language":                         "Язык",
+	"Original language":                "Язык оригинала",
+	"Original title":                   "Название оригинала",
+	"Location":                         "Место",
+	"Format":                           "Формат",
+	"Finished":                         "Прочитана",
+	"Pages":                            "Страниц",
+	"Purchased":                        "Куплена",
+	"Price":                            "Цена",
+	"Estimated value":                  "Оценочная стоимость",
+	"Cover":                            "Обложка",
+	"Related":                          "Связи",
+	"%s: %s (ID %d)":                   "%s: %s (ID %d)",
+	"Notes":                            "Заметки",
+	"Quotes":                           "Цитаты",
+	"“%s”":                             "«%s»",
+	"sequel of":                        "продолжение",
+	"followed by":                      "продолжается в",
+	"translation of":                   "перевод",
+	"translated as":                    "переведена как",
+	"contains":                         "содержит",
+	"contained in":                     "входит в",
+	"companion to":                     "дополняет",
+	"read":                             "прочитана",
+	"unread":                           "не прочитана",
+
+	// cmd/pick.go
+	"Pick an unread book to read next": "Выбрать непрочитанную книгу, которую читать следующей",
+	"Pick an unread book to read next, at random with weights.": `Выбирает непрочитанную книгу, которую читать следующей, случайно и с
+весами.`,
+	`Every unread book starts with weight 1. A book on the shelf for a while
+gains 1 for every year since its purchase date, so long neglected books
+come up more often. A book matching a --prefer filter expression weighs
+3 times as much, for each expression it matches. A book by an author
+you finished another book of within --recent-days weighs 1/4 as much.`: `У каждой непрочитанной книги вес 1. Книга, которая давно стоит на полке,
+получает 1 за каждый год с даты покупки, так что давно забытые книги
+выпадают чаще. Книга, подходящая под выражение фильтра в --prefer, весит
+в 3 раза больше за каждое такое выражение. Книга автора, другую книгу
+которого вы дочитали за последние --recent-days дней, весит в 4 раза
+меньше.`,
+	`--where leaves out the books that do not match a filter expression, and
+--min-pages and --max-pages those of other lengths; books without a page
+count are kept:`: `--where отбрасывает книги, не подходящие под выражение фильтра, а
+--min-pages и --max-pages — книги другой длины; книги без числа страниц
+остаются:`,
+	`The result lists the candidates with the highest weights, their chance
+of being picked and why they weigh what they do. Picks are random; the
+seed printed with one repeats it when given with --seed.`: `В результате перечислены кандидаты с наибольшими весами, их шансы быть
+выбранными и почему у них такой вес. Выбор случаен; напечатанное с ним
+зерно повторяет его, если передать его в --seed.`,
+	"Only pick books matching a filter expression, e.g. 'year >= 1900'":       "Выбирать только книги, подходящие под выражение фильтра, например 'year >= 1900'",
+	"Weigh books matching a filter expression higher (repeatable)":            "Повысить вес книг, подходящих под выражение фильтра (можно повторять)",
+	"Number of candidates to explain (0: all)":                                "Сколько кандидатов показать (0: всех)",
+	"Only pick books of at least this many pages":                             "Выбирать только книги не короче стольких страниц",
+	"Only pick books of at most this many pages":                              "Выбирать только книги не длиннее стольких страниц",
+	"Days within which a finished author's other books weigh less (0: never)": "Сколько дней после прочтения книги другие книги автора весят меньше (0: не учитывать)",
+	"invalid --min-pages %d":                                                  "недопустимое значение --min-pages %d",
+	"invalid --max-pages %d":                                                  "недопустимое значение --max-pages %d",
+	"invalid --recent-days %d":                                                "недопустимое значение --recent-days %d",
+	"author read on %s (÷%d)":                                                 "автор прочитан %s (÷%d)",
+	"Seed of the random pick, to repeat an earlier one":                       "Зерно случайного выбора, чтобы повторить прежний выбор",
+	"no unread books to pick from":                                            "нет непрочитанных книг, из которых можно выбрать",
+	"failed to apply --prefer %q: %w":                                         "не удалось применить --prefer %q: %w",
+	"on the shelf %s years (+%s)":                                             "на полке %s года (+%s)",
+	"matches %q (×%d)":                                                        "подходит под %q (×%d)",
+	"Read next: %s by %s (ID %d)":                                             "Читать следующей: %s — %s (ID %d)",
+	"TITLE":                                                                   "НАЗВАНИЕ",
+	"AUTHOR":                                                                  "АВТОР",
+	"WEIGHT":                                                                  "ВЕС",
+	"CHANCE":                                                                  "ШАНС",
+	"WHY":                                                                     "ПОЧЕМУ",
+
+	// cmd/quote.go, cmd/relate.go
+	"Keep quotes from books":                "Хранить цитаты из книг",
+	"Add a quote from a book":               "Добавить цитату из книги",
+	"List the quotes from a book":           "Перечислить цитаты из книги",
+	"Delete a quote":                        "Удалить цитату",
+	"Page the quote is on":                  "Страница, на которой цитата",
+	"failed to add quote: %w":               "не удалось добавить цитату: %w",
+	"Quote with ID %d added successfully":   "Цитата с ID %d добавлена",
+	"invalid quote ID %q":                   "недопустимый ID цитаты %q",
+	"failed to delete quote: %w":            "не удалось удалить цитату: %w",
+	"Quote with ID %d deleted successfully": "Цитата с ID %d удалена",
+	"No quotes found":                       "Цитаты не найдены",
+	", page %d":                             ", с. %d",
+	"Relate two books (sequel-of, translation-of, contains, companion-to)": "Связать две книги (sequel-of, translation-of, contains, companion-to)",
+	"Relate two books.": "Связывает две книги.",
+	`  book relate 12 contains 7        omnibus 12 contains novel 7
+  book relate 8 translation-of 3   book 8 is a translation of book 3
+  book relate 5 sequel-of 4        book 5 continues book 4
+  book relate 9 companion-to 4     books 9 and 4 belong together`: `  book relate 12 contains 7        сборник 12 содержит роман 7
+  book relate 8 translation-of 3   книга 8 — перевод книги 3
+  book relate 5 sequel-of 4        книга 5 продолжает книгу 4
+  book relate 9 companion-to 4     книги 9 и 4 дополняют друг друга`,
+	"Relations that would form a cycle are rejected.": "Связи, образующие цикл, не допускаются.",
+	"Remove the relation instead of adding it":        "Удалить связь, а не добавить",
+	"invalid relation: %v":                            "недопустимая связь: %v",
+	"failed to remove relation: %w":                   "не удалось удалить связь: %w",
+	"Relation %d %s %d removed successfully":          "Связь %d %s %d удалена",
+	"failed to relate books: %w":                      "не удалось связать книги: %w",
+	"Relation %d %s %d added successfully":            "Связь %d %s %d добавлена",
+
+	// cmd/stats.go, cmd/valuation.go
+	"Show book statistics":                               "Показать статистику книг",
+	"Show statistics by publication year":                "Статистика по годам издания",
+	"Show statistics by author":                          "Статистика по авторам",
+	"Show read/unread statistics":                        "Статистика прочитанных и непрочитанных",
+	"Show statistics by edition language":                "Статистика по языкам изданий",
+	"Show translations by original and edition language": "Переводы по языкам оригинала и издания",
+	"failed to count books: %w":                          "не удалось подсчитать книги: %w",
+	"failed to count translations: %w":                   "не удалось подсчитать переводы: %w",
+	"STATISTIC":                                          "ПОКАЗАТЕЛЬ",
+	"Total books":                                        "Всего книг",
+	"Read":                                               "Прочитано",
+	"Unread":                                             "Не прочитано",
+	"YEAR":                                               "ГОД",
+	"STATUS":                                             "СТАТУС",
+	"LANGUAGE":                                           "ЯЗЫК",
+	"COUNT":                                              "КОЛИЧЕСТВО",
+	"(unknown)":                                          "(неизвестно)",
+	"Translations":                                       "Переводы",
+	"Originals":                                          "Оригиналы",
+	"FROM":                                               "С ЯЗЫКА",
+	"INTO":                                               "НА ЯЗЫК",
+	"Print a valuation report of the collection":                         "Вывести отчёт об оценке коллекции",
+	"Print a valuation report of the collection for insurance purposes.": "Выводит отчёт об оценке коллекции для страхования.",
+	`Every priced book is listed with its purchase price and value, followed by
+totals per currency, per location and per format. The value of a book is
+its estimated value when set and its purchase price otherwise. Amounts in
+different currencies are never added up.`: `Перечисляются все книги с ценой, с ценой покупки и стоимостью, а за ними
+итоги по валютам, местам и форматам. Стоимость книги — её оценочная
+стоимость, если она задана, иначе цена покупки. Суммы в разных валютах
+никогда не складываются.`,
+	"COLLECTION VALUATION REPORT — %s": "ОТЧЁТ ОБ ОЦЕНКЕ КОЛЛЕКЦИИ — %s",
+	"LOCATION":                         "МЕСТО",
+	"FORMAT":                           "ФОРМАТ",
+	"PURCHASED":                        "КУПЛЕНА",
+	"PRICE":                            "ЦЕНА",
+	"CURRENCY":                         "ВАЛЮТА",
+	"BOOKS":                            "КНИГ",
+	"PAID":                             "УПЛАЧЕНО",
+	"amount\x04VALUE":                  "СТОИМОСТЬ",
+
+	// cmd/view.go
+	"Save and run searches":  "Сохранять и выполнять поиски",
+	"Save and run searches.": "Сохраняет и выполняет поиски.",
+	`A view is a filter expression and sort order, as taken by list, saved
+under a name:`: `Представление — это выражение фильтра и порядок сортировки, как у list,
+сохранённые под именем:`,
+	`Views are stored in the library and run on its current books every time.
+They also appear as tabs in book interactive.`: `Представления хранятся в библиотеке и каждый раз выполняются на её
+текущих книгах. Они также видны вкладками в book interactive.`,
+	"Save a view, replacing one of the same name": "Сохранить представление, заменив одноимённое",
+	"List the books of a view":                    "Вывести книги представления",
+	"List the saved views":                        "Перечислить сохранённые представления",
+	"Delete a saved view":                         "Удалить сохранённое представление",
+	"failed to save view: %w":                     "не удалось сохранить представление: %w",
+	"View %q saved":                               "Представление %q сохранено",
+	"failed to find view: %w":                     "не удалось найти представление: %w",
+	"failed to find views: %w":                    "не удалось найти представления: %w",
+	"failed to delete view: %w":                   "не удалось удалить представление: %w",
+	"View %q deleted":                             "Представление %q удалено",
+	"No views saved":                              "Сохранённых представлений нет",
+	"NAME":                                        "ИМЯ",
+	"WHERE":                                       "ФИЛЬТР",
+	"SORT":                                        "СОРТИРОВКА",
+
+	// tui
+	"empty image":                  "пустое изображение",
+	"unknown theme %q (valid: %s)": "неизвестная тема %q (допустимые: %s)",
+	"Error loading views:":         "Ошибка загрузки представлений:",
+	"Error running view:":          "Ошибка выполнения представления:",
+	"Error searching books:":       "Ошибка поиска книг:",
+	"Error:":                       "Ошибка:",
+	"Error loading author:":        "Ошибка загрузки автора:",
+	"Error loading related books:": "Ошибка загрузки связанных книг:",
+	"Error rendering cover:":       "Ошибка отрисовки обложки:",
+	"Error deleting book:":         "Ошибка удаления книги:",
+	"Error updating status:":       "Ошибка изменения статуса:",
+	"Your Book Collection":         "Ваша библиотека",
+	"All books":                    "Все книги",
+	"Search: %s":                   "Поиск: %s",
+	"Search: %s (%s)":              "Поиск: %s (%s)",
+	"%s by %s (%d)":                "%s — %s (%d)",
+	"↑/↓: Navigate • Enter: Details • /: Search • a: Add • d: Delete • t: Toggle status • s: Stats • q: Quit":                  "↑/↓: Навигация • Enter: Подробно • /: Поиск • a: Добавить • d: Удалить • t: Сменить статус • s: Статистика • q: Выход",
+	"↑/↓: Navigate • Tab: Next view • Enter: Details • /: Search • a: Add • d: Delete • t: Toggle status • s: Stats • q: Quit": "↑/↓: Навигация • Tab: Следующее представление • Enter: Подробно • /: Поиск • a: Добавить • d: Удалить • t: Сменить статус • s: Статистика • q: Выход",
+	"Enter: Search • Esc: Cancel": "Enter: Искать • Esc: Отмена",
+	"↑/↓: Navigate • Enter: Details • /: New search • Esc: Show all books": "↑/↓: Навигация • Enter: Подробно • /: Новый поиск • Esc: Все книги",
+	"Author:":            "Автор:",
+	"Year:":              "Год:",
+	"Status:":            "Статус:",
+	"Publisher:":         "Издательство:",
+	"Language:":          "Язык:",
+	"Original language:": "Язык оригинала:",
+	"Original title:":    "Название оригинала:",
+	"ISBN:":              "ISBN:",
+	"Pages:":             "Страниц:",
+	"Location:":          "Место:",
+	"Format:":            "Формат:",
+	"Value:":             "Стоимость:",
+	"Related books:":     "Связанные книги:",
+	"Esc: Back to list":  "Esc: Назад к списку",
+	"Add New Book":       "Новая книга",
+	"Title:":             "Название:",
+	"Tab/Shift+Tab: Move between fields • Space: Toggle status • Enter: Save • Esc: Cancel": "Tab/Shift+Tab: Переход между полями • Пробел: Сменить статус • Enter: Сохранить • Esc: Отмена",
+	"Statistics":   "Статистика",
+	"Total books:": "Всего книг:",
+	"Read:":        "Прочитано:",
+	"Unread:":      "Не прочитано:",
+
+	// internal/repository
+	"book with ID %d not found":    "книга с ID %d не найдена",
+	"book with ID %d: %w":          "книга с ID %d: %w",
+	"quote with ID %d not found":   "цитата с ID %d не найдена",
+	"author with ID %d not found":  "автор с ID %d не найден",
+	"author %q not found":          "автор %q не найден",
+	"alias %q contains no letters": "в псевдониме %q нет букв",
+	"%q already belongs to author with ID %d; merge the authors instead": "%q уже принадлежит автору с ID %d; объедините авторов",
+	"cannot merge author with ID %d into itself":                         "нельзя объединить автора с ID %d с самим собой",
+	"cannot merge book with ID %d into itself":                           "нельзя объединить книгу с ID %d с самой собой",
+	"book with ID %d cannot be related to itself":                        "книгу с ID %d нельзя связать с самой собой",
+	"book %d %s book %d would create a cycle":                            "связь «книга %d %s книга %d» образует цикл",
+	"book %d is not %s book %d":                                          "нет связи «книга %d %s книга %d»",
+	"a view needs a name":                                                "у представления должно быть имя",
+	"view %q not found":                                                  "представление %q не найдено",
+	"invalid page cursor %q":                                             "недопустимый курсор страницы %q",
+	"page cursor was created for --sort %s, not %s":                      "курсор страницы создан для --sort %s, а не %s",
+	"empty search query":                                                 "пустой поисковый запрос",
+	"unterminated phrase in search query":                                "в поисковом запросе не закрыта фраза",
+	"%s must stand between two search terms":                             "%s должен стоять между двумя словами запроса",
+	"no values are collected for field %q":                               "значения поля %q не собираются",
+
+	// internal/validation, internal/models
+	"is required":                                       "обязательно",
+	"must be between %d and %d":                         "должно быть от %d до %d",
+	"must be one of %s":                                 "должно быть одним из: %s",
+	"must not be negative":                              "не может быть отрицательным",
+	"%q is not a valid ISBN-10 or ISBN-13":              "%q — недопустимый ISBN-10 или ISBN-13",
+	"%q is not a BCP-47 language tag, e.g. ru or en-GB": "%q — не тег языка BCP-47, например ru или en-GB",
+	"%q is not a date, expected YYYY-MM-DD":             "%q — не дата, ожидается ГГГГ-ММ-ДД",
+	"is required when a price or value is set":          "обязательно, если указана цена или стоимость",
+	"%q is not an ISO 4217 code such as EUR":            "%q — не код ISO 4217, например EUR",
+	"invalid amount %q":                                 "недопустимая сумма %q",
+	"invalid amount %q: at most two decimal places":     "недопустимая сумма %q: не больше двух знаков после запятой",
+	"unknown relation type %q (valid: %s)":              "неизвестный тип связи %q (допустимые: %s)",
+
+	// internal/filter
+	"column %d: %s":     "позиция %d: %s",
+	"end of expression": "конец выражения",
+	"word":              "слово",
+	"string":            "строка",
+	"number":            "число",
+	"operator":          "оператор",
+	"token":             "лексема",
+	`unexpected %q, did you mean "!=" or "!~"?`:                   `неожиданный символ %q; возможно, имелось в виду "!=" или "!~"?`,
+	"unexpected character %q":                                     "неожиданный символ %q",
+	"unterminated string":                                         "не закрыта строка",
+	`unexpected ")" without matching "("`:                         `лишняя ")" без парной "("`,
+	`unexpected %s %q, expected "and", "or" or end of expression`: `неожиданно: %s %q; ожидается "and", "or" или конец выражения`,
+	`missing ")" for this "("`:                                    `нет ")" для этой "("`,
+	`expected ")", got %s %q`:                                     `ожидается ")", а не %s %q`,
+	"expected a field name, got end of expression":                "ожидается имя поля, а выражение закончилось",
+	"expected a field name, got %s %q":                            "ожидается имя поля, а не %s %q",
+	"unknown field %q (valid: %s)":                                "неизвестное поле %q (допустимые: %s)",
+	"expected an operator (=, !=, <, <=, >, >=, ~, !~) after %q":  "после %q ожидается оператор (=, !=, <, <=, >, >=, ~, !~)",
+	"expected a value after %q":                                   "после %q ожидается значение",
+	"expected a value after %q, got %s %q":                        "после %q ожидается значение, а не %s %q",
+	"operator %q only applies to text fields":                     "оператор %q применим только к текстовым полям",
+	"%q is not a number":                                          "%q — не число",
+	"unknown sort field %q (valid: %s)":                           "неизвестное поле сортировки %q (допустимые: %s)",
+
+	// pkg/db
+	"failed to open database: %v":       "не удалось открыть базу данных: %v",
+	"failed to read schema version: %v": "не удалось прочитать версию схемы: %v",
+	"failed to start migration %d: %v":  "не удалось начать миграцию %d: %v",
+	"failed to apply migration %d: %v":  "не удалось применить миграцию %d: %v",
+	"failed to record migration %d: %v": "не удалось записать миграцию %d: %v",
+	"failed to commit migration %d: %v": "не удалось завершить миграцию %d: %v",
+	"statuses other than read or unread in books %s: set them to read or unread in %s, e.g. with sqlite3, and try again": "статусы, отличные от read и unread, у книг %s: исправьте их на read или unread в %s, например с помощью sqlite3, и повторите",
+	"failed to read collation locale: %v":   "не удалось прочитать язык сортировки: %v",
+	"failed to update collation: %v":        "не удалось обновить сортировку: %v",
+	"failed to detach search index: %v":     "не удалось отключить поисковый индекс: %v",
+	"failed to update search index: %v":     "не удалось обновить поисковый индекс: %v",
+	"failed to create search documents: %v": "не удалось создать поисковые документы: %v",
+	"failed to read search index: %v":       "не удалось прочитать поисковый индекс: %v",
+	"failed to build search index: %v":      "не удалось построить поисковый индекс: %v",
+	"a batch is already open":               "пакет уже открыт",
+	"failed to start batch: %v":             "не удалось начать пакет: %v",
+}
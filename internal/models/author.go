@@ -0,0 +1,11 @@
+package models
+
+type Author struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`      // canonical display name
+	SortName  string   `json:"sort_name"` // e.g. "Tolkien, J. R. R."
+	BirthYear int      `json:"birth_year"`
+	DeathYear int      `json:"death_year"`
+	Country   string   `json:"country"`
+	Aliases   []string `json:"aliases"` // pen names and spelling variants resolving to this author
+}
@@ -1,9 +1,44 @@
 package models
 
 type Book struct {
-	ID            int
-	Title         string
-	Author        string
-	PublishedYear int
-	Status        string
+	ID               int    `json:"id"`
+	Title            string `json:"title"`
+	Author           string `json:"author"`    // author as credited on this edition
+	AuthorID         int    `json:"author_id"` // resolved from Author, see Author.Aliases
+	PublishedYear    int    `json:"year"`
+	Status           string `json:"status"`
+	ReadDate         string `json:"read_date"` // YYYY-MM-DD when last finished, set on marking it read
+	Publisher        string `json:"publisher"`
+	Language         string `json:"language"`          // BCP-47 tag of this edition, e.g. "ru" or "en-GB"
+	OriginalLanguage string `json:"original_language"` // BCP-47 tag of the work this edition was translated from
+	OriginalTitle    string `json:"original_title"`
+	ISBN             string `json:"isbn"`          // ISBN-10 or ISBN-13 without hyphens
+	Pages            int    `json:"pages"`         // 0 if unknown
+	Cover            string `json:"cover"`         // file name inside the covers directory
+	Location         string `json:"location"`      // where the copy is kept, e.g. "living room"
+	Format           string `json:"format"`        // hardcover, paperback, ebook, audiobook or other
+	PurchaseDate     string `json:"purchase_date"` // YYYY-MM-DD
+	PurchasePrice    Amount `json:"purchase_price"`
+	Currency         string `json:"currency"`        // ISO 4217 code of the amounts
+	EstimatedValue   Amount `json:"estimated_value"` // current value, 0 if unknown
+	Notes            string `json:"notes"`
+}
+
+var Formats = []string{"hardcover", "paperback", "ebook", "audiobook", "other"}
+
+var Statuses = []string{"read", "unread"}
+
+// Value returns the best known worth of the copy: the estimated value when
+// set, the purchase price otherwise.
+func (b Book) Value() Amount {
+	if b.EstimatedValue != 0 {
+		return b.EstimatedValue
+	}
+	return b.PurchasePrice
+}
+
+// IsTranslation reports whether the edition is in a different language
+// than the original work.
+func (b Book) IsTranslation() bool {
+	return b.OriginalLanguage != "" && b.OriginalLanguage != b.Language
 }
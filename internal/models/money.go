@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+// Amount is a sum of money in hundredths of the currency unit.
+type Amount int64
+
+// ParseAmount parses a decimal amount such as "12.5" or "1200": digits,
+// optionally followed by a point or comma and one or two digits.
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, ",", "."))
+	whole, frac, point := strings.Cut(s, ".")
+	if !isDigits(whole) || point && !isDigits(frac) {
+		return 0, i18n.Errorf("invalid amount %q", s)
+	}
+	if len(frac) > 2 {
+		return 0, i18n.Errorf("invalid amount %q: at most two decimal places", s)
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/100-1 {
+		return 0, i18n.Errorf("invalid amount %q", s)
+	}
+	var cents int64
+	if frac != "" {
+		cents, _ = strconv.ParseInt((frac + "0")[:2], 10, 64)
+	}
+	return Amount(units*100 + cents), nil
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount as a decimal with two places.
+func (a Amount) String() string {
+	return fmt.Sprintf("%d.%02d", a/100, a%100)
+}
+
+// MarshalJSON encodes the amount as a decimal number, e.g. 12.50.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
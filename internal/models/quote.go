@@ -0,0 +1,8 @@
+package models
+
+type Quote struct {
+	ID     int    `json:"id"`
+	BookID int    `json:"book_id"`
+	Text   string `json:"text"`
+	Page   int    `json:"page"` // 0 if unknown
+}
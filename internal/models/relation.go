@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+// RelationType describes how one book record relates to another.
+type RelationType string
+
+const (
+	SequelOf      RelationType = "sequel-of"
+	TranslationOf RelationType = "translation-of"
+	Contains      RelationType = "contains"
+	CompanionTo   RelationType = "companion-to"
+)
+
+var RelationTypes = []RelationType{SequelOf, TranslationOf, Contains, CompanionTo}
+
+func ParseRelationType(s string) (RelationType, error) {
+	for _, t := range RelationTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	valid := make([]string, len(RelationTypes))
+	for i, t := range RelationTypes {
+		valid[i] = string(t)
+	}
+	return "", i18n.Errorf("unknown relation type %q (valid: %s)", s, strings.Join(valid, ", "))
+}
+
+// Symmetric reports whether the relation reads the same in both directions.
+func (t RelationType) Symmetric() bool {
+	return t == CompanionTo
+}
+
+// Relation is a relation of a book as seen from that book. Incoming
+// relations were recorded on the other book.
+type Relation struct {
+	Type         RelationType `json:"type"`
+	RelatedID    int          `json:"related_id"`
+	RelatedTitle string       `json:"related_title"`
+	Incoming     bool         `json:"incoming"`
+}
+
+// Label describes the relation from the point of view of the book it
+// belongs to, e.g. "contained in" for an incoming "contains".
+func (r Relation) Label() string {
+	if !r.Incoming || r.Type.Symmetric() {
+		return strings.ReplaceAll(string(r.Type), "-", " ")
+	}
+	switch r.Type {
+	case SequelOf:
+		return "followed by"
+	case TranslationOf:
+		return "translated as"
+	case Contains:
+		return "contained in"
+	}
+	return string(r.Type)
+}
@@ -0,0 +1,9 @@
+package models
+
+// View is a saved search: a filter expression and sort order listed under
+// a name, see package filter.
+type View struct {
+	Name  string `json:"name"`
+	Where string `json:"where"`
+	Sort  string `json:"sort"`
+}
@@ -0,0 +1,38 @@
+// Package names normalizes personal names so that spelling variants of the
+// same author compare equal.
+package names
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Key returns the comparison key of a name: letters and digits only, case
+// folded. "J.R.R. Tolkien" and "J. R. R. Tolkien" share the key "jrrtolkien".
+func Key(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// Tidy collapses whitespace and puts a space after every initial,
+// so "J.R.R.  Tolkien" becomes "J. R. R. Tolkien".
+func Tidy(name string) string {
+	name = strings.ReplaceAll(name, ".", ". ")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// SortName builds a library sort name from a display name by moving the
+// last word to the front: "J.R.R. Tolkien" becomes "Tolkien, J. R. R.".
+func SortName(name string) string {
+	words := strings.Fields(Tidy(name))
+	if len(words) < 2 {
+		return strings.Join(words, " ")
+	}
+	last := words[len(words)-1]
+	return last + ", " + strings.Join(words[:len(words)-1], " ")
+}
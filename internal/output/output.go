@@ -0,0 +1,269 @@
+// Package output renders command results in the formats selected with the
+// global --output and --template flags.
+//
+// Values are described by their json struct tags, so every format uses the
+// same field names. Types can tune their presentation by implementing
+// TableWriter (human readable table output) and Lister (the records to
+// emit one per line or row in jsonl, csv, tsv and template output).
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+type Format string
+
+const (
+	Table    Format = "table"
+	JSON     Format = "json"
+	JSONL    Format = "jsonl"
+	CSV      Format = "csv"
+	TSV      Format = "tsv"
+	YAML     Format = "yaml"
+	Template Format = "template"
+)
+
+var Formats = []Format{Table, JSON, JSONL, CSV, TSV, YAML, Template}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown output format %q (valid: %s)", s, strings.Join(names, ", "))
+}
+
+// TableWriter is implemented by values with a hand-made table layout.
+type TableWriter interface {
+	WriteTable(w io.Writer) error
+}
+
+// Lister is implemented by values wrapping a list of records, such as a
+// page of books with pagination metadata. Line and row oriented formats
+// render the records; json and yaml render the whole value.
+type Lister interface {
+	Items() any
+}
+
+type Renderer struct {
+	Format   Format
+	template *template.Template
+}
+
+// New creates a renderer. A non-empty tmpl selects the template format.
+func New(format Format, tmpl string) (*Renderer, error) {
+	r := &Renderer{Format: format}
+	if tmpl != "" {
+		t, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %v", err)
+		}
+		r.Format = Template
+		r.template = t
+	}
+	if r.Format == Template && r.template == nil {
+		return nil, fmt.Errorf("--output template requires --template")
+	}
+	return r, nil
+}
+
+// Structured reports whether the output is meant for programs rather than people.
+func (r *Renderer) Structured() bool {
+	return r.Format != Table
+}
+
+func (r *Renderer) Render(w io.Writer, v any) error {
+	switch r.Format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		return writeYAML(w, v)
+	case JSONL:
+		enc := json.NewEncoder(w)
+		for _, item := range records(v) {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV, TSV:
+		return r.writeDelimited(w, v)
+	case Template:
+		for _, item := range records(v) {
+			if err := r.template.Execute(w, item); err != nil {
+				return err
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+
+	if tw, ok := v.(TableWriter); ok {
+		return tw.WriteTable(w)
+	}
+	return writeTable(w, v)
+}
+
+// records returns the values emitted one per line: the items of a Lister,
+// the elements of a slice, or the value itself.
+func records(v any) []any {
+	if l, ok := v.(Lister); ok {
+		v = l.Items()
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []any{v}
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items
+}
+
+func (r *Renderer) writeDelimited(w io.Writer, v any) error {
+	cw := csv.NewWriter(w)
+	if r.Format == TSV {
+		cw.Comma = '\t'
+	}
+
+	items := records(v)
+	if len(items) == 0 {
+		cw.Flush()
+		return cw.Error()
+	}
+	columns := columnsOf(items[0])
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := cw.Write(cells(columns, item)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTable is the generic table layout: one column per field.
+func writeTable(w io.Writer, v any) error {
+	items := records(v)
+	if len(items) == 0 {
+		return nil
+	}
+	columns := columnsOf(items[0])
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range columns {
+		fmt.Fprintf(tw, "%s\t", strings.ToUpper(strings.ReplaceAll(c.name, "_", " ")))
+	}
+	fmt.Fprintln(tw)
+	for _, item := range items {
+		fmt.Fprintln(tw, strings.Join(cells(columns, item), "\t")+"\t")
+	}
+	return tw.Flush()
+}
+
+type column struct {
+	name  string
+	index []int
+}
+
+// columnsOf lists the json-tagged fields of a struct, flattening embedded
+// structs the way encoding/json does. Non-struct values have one column.
+func columnsOf(v any) []column {
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Struct {
+		return []column{{name: "value"}}
+	}
+	var columns []column
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			idx := append(append([]int{}, index...), i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				walk(f.Type, idx)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			columns = append(columns, column{name: name, index: idx})
+		}
+	}
+	walk(t, nil)
+
+	// A field hides fields of the same name nested deeper, as in encoding/json.
+	var visible []column
+	for _, c := range columns {
+		hidden := false
+		for _, other := range columns {
+			if other.name == c.name && len(other.index) < len(c.index) {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
+
+func cells(columns []column, v any) []string {
+	rv := reflect.ValueOf(v)
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		if c.index == nil {
+			row[i] = formatCell(rv)
+			continue
+		}
+		row[i] = formatCell(rv.FieldByIndex(c.index))
+	}
+	return row
+}
+
+// formatCell renders scalars with fmt and nested values as JSON.
+func formatCell(v reflect.Value) string {
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Pointer:
+		if v.Kind() == reflect.Slice && v.Len() == 0 {
+			return ""
+		}
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return string(data)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/names"
+)
+
+const authorColumns = `id, name, sort_name, birth_year, death_year, country`
+
+type AuthorRepository struct {
+	db *sql.DB
+}
+
+func NewAuthorRepository(db *sql.DB) *AuthorRepository {
+	return &AuthorRepository{db: db}
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// resolveAuthor returns the ID of the author known under name, creating a
+// new author record when no name or alias matches. Names without letters
+// resolve to no author (ID 0).
+func resolveAuthor(q querier, name string) (int, error) {
+	key := names.Key(name)
+	if key == "" {
+		return 0, nil
+	}
+
+	var id int
+	err := q.QueryRow("SELECT author_id FROM author_aliases WHERE alias_key = ?", key).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	name = names.Tidy(name)
+	result, err := q.Exec("INSERT INTO authors (name, sort_name) VALUES (?, ?)", name, names.SortName(name))
+	if err != nil {
+		return 0, err
+	}
+	newID, _ := result.LastInsertId()
+	_, err = q.Exec("INSERT INTO author_aliases (alias_key, alias, author_id) VALUES (?, ?, ?)", key, name, newID)
+	return int(newID), err
+}
+
+func scanAuthor(row rowScanner) (models.Author, error) {
+	var a models.Author
+	var born, died sql.NullInt64
+	err := row.Scan(&a.ID, &a.Name, &a.SortName, &born, &died, &a.Country)
+	a.BirthYear = int(born.Int64)
+	a.DeathYear = int(died.Int64)
+	return a, err
+}
+
+func (r *AuthorRepository) aliases(id int) ([]string, error) {
+	rows, err := r.db.Query("SELECT alias FROM author_aliases WHERE author_id = ? ORDER BY alias COLLATE UNICODE", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aliases []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+func (r *AuthorRepository) GetAllAuthors() ([]models.Author, error) {
+	rows, err := r.db.Query("SELECT " + authorColumns + " FROM authors ORDER BY sort_name COLLATE UNICODE")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authors []models.Author
+	for rows.Next() {
+		a, err := scanAuthor(rows)
+		if err != nil {
+			return nil, err
+		}
+		authors = append(authors, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range authors {
+		authors[i].Aliases, err = r.aliases(authors[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return authors, nil
+}
+
+func (r *AuthorRepository) GetAuthorByID(id int) (models.Author, error) {
+	row := r.db.QueryRow("SELECT "+authorColumns+" FROM authors WHERE id = ?", id)
+	a, err := scanAuthor(row)
+	if err == sql.ErrNoRows {
+		return a, errorf(ErrNotFound, "author with ID %d not found", id)
+	}
+	if err != nil {
+		return a, err
+	}
+	a.Aliases, err = r.aliases(id)
+	return a, err
+}
+
+// GetAuthorByName finds an author by canonical name or any alias.
+func (r *AuthorRepository) GetAuthorByName(name string) (models.Author, error) {
+	var id int
+	err := r.db.QueryRow("SELECT author_id FROM author_aliases WHERE alias_key = ?", names.Key(name)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return models.Author{}, errorf(ErrNotFound, "author %q not found", name)
+	}
+	if err != nil {
+		return models.Author{}, err
+	}
+	return r.GetAuthorByID(id)
+}
+
+// UpdateAuthor stores the profile fields of an author. Renaming an author
+// keeps the old name as an alias.
+func (r *AuthorRepository) UpdateAuthor(author models.Author) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE authors SET name = ?, sort_name = ?, birth_year = ?, death_year = ?, country = ?
+		WHERE id = ?`
+	result, err := tx.Exec(query, author.Name, author.SortName, nullInt(author.BirthYear),
+		nullInt(author.DeathYear), author.Country, author.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errorf(ErrNotFound, "author with ID %d not found", author.ID)
+	}
+
+	if err := addAlias(tx, author.ID, author.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddAlias registers another name under which the author is known.
+func (r *AuthorRepository) AddAlias(id int, alias string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM authors WHERE id = ?", id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return errorf(ErrNotFound, "author with ID %d not found", id)
+	}
+
+	if err := addAlias(tx, id, alias); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func addAlias(q querier, id int, alias string) error {
+	alias = names.Tidy(alias)
+	key := names.Key(alias)
+	if key == "" {
+		return errorf(ErrValidation, "alias %q contains no letters", alias)
+	}
+
+	var owner int
+	err := q.QueryRow("SELECT author_id FROM author_aliases WHERE alias_key = ?", key).Scan(&owner)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = q.Exec("INSERT INTO author_aliases (alias_key, alias, author_id) VALUES (?, ?, ?)", key, alias, id)
+		return err
+	case err != nil:
+		return err
+	case owner != id:
+		return errorf(ErrConflict, "%q already belongs to author with ID %d; merge the authors instead", alias, owner)
+	}
+	return nil
+}
+
+// MergeAuthors folds the author dupID into keepID: books and aliases move
+// over, profile fields missing on keepID are taken from dupID, and dupID
+// is deleted.
+func (r *AuthorRepository) MergeAuthors(keepID, dupID int) error {
+	if keepID == dupID {
+		return errorf(ErrValidation, "cannot merge author with ID %d into itself", keepID)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	keep, err := scanAuthor(tx.QueryRow("SELECT "+authorColumns+" FROM authors WHERE id = ?", keepID))
+	if err == sql.ErrNoRows {
+		return errorf(ErrNotFound, "author with ID %d not found", keepID)
+	}
+	if err != nil {
+		return err
+	}
+	dup, err := scanAuthor(tx.QueryRow("SELECT "+authorColumns+" FROM authors WHERE id = ?", dupID))
+	if err == sql.ErrNoRows {
+		return errorf(ErrNotFound, "author with ID %d not found", dupID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if keep.BirthYear == 0 {
+		keep.BirthYear = dup.BirthYear
+	}
+	if keep.DeathYear == 0 {
+		keep.DeathYear = dup.DeathYear
+	}
+	if strings.TrimSpace(keep.Country) == "" {
+		keep.Country = dup.Country
+	}
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{"UPDATE books SET author_id = ? WHERE author_id = ?", []any{keepID, dupID}},
+		{"UPDATE author_aliases SET author_id = ? WHERE author_id = ?", []any{keepID, dupID}},
+		{"UPDATE authors SET birth_year = ?, death_year = ?, country = ? WHERE id = ?",
+			[]any{nullInt(keep.BirthYear), nullInt(keep.DeathYear), keep.Country, keepID}},
+		{"DELETE FROM authors WHERE id = ?", []any{dupID}},
+	}
+	for _, st := range statements {
+		if _, err := tx.Exec(st.query, st.args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// nullInt stores zero as NULL for optional integer columns.
+func nullInt(v int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v), Valid: v != 0}
+}
@@ -2,11 +2,19 @@ package repository
 
 import (
 	"database/sql"
-	"fmt"
+	"time"
 
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/filter"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
 	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/validation"
 )
 
+const bookColumns = `id, title, author, author_id, published_year, status,
+	publisher, language, original_language, original_title, cover,
+	location, format, purchase_date, purchase_price, currency, estimated_value, notes, isbn,
+	pages, read_date`
+
 type BookRepository struct {
 	db *sql.DB
 }
@@ -15,8 +23,26 @@ func NewBookRepository(db *sql.DB) *BookRepository {
 	return &BookRepository{db: db}
 }
 
-func (r *BookRepository) GetAllBooks() ([]models.Book, error) {
-	rows, err := r.db.Query("SELECT id, title, author, published_year, status FROM books")
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBook(row rowScanner) (models.Book, error) {
+	var b models.Book
+	var authorID, year sql.NullInt64
+	var status sql.NullString
+	err := row.Scan(&b.ID, &b.Title, &b.Author, &authorID, &year, &status,
+		&b.Publisher, &b.Language, &b.OriginalLanguage, &b.OriginalTitle, &b.Cover,
+		&b.Location, &b.Format, &b.PurchaseDate, &b.PurchasePrice, &b.Currency, &b.EstimatedValue, &b.Notes, &b.ISBN,
+		&b.Pages, &b.ReadDate)
+	b.AuthorID = int(authorID.Int64)
+	b.PublishedYear = int(year.Int64)
+	b.Status = status.String
+	return b, err
+}
+
+func (r *BookRepository) queryBooks(query string, args ...any) ([]models.Book, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -24,67 +50,250 @@ func (r *BookRepository) GetAllBooks() ([]models.Book, error) {
 
 	var books []models.Book
 	for rows.Next() {
-		var b models.Book
-		err := rows.Scan(&b.ID, &b.Title, &b.Author, &b.PublishedYear, &b.Status)
+		b, err := scanBook(rows)
 		if err != nil {
 			return nil, err
 		}
 		books = append(books, b)
 	}
-	return books, nil
+	return books, rows.Err()
 }
 
-func (r *BookRepository) GetFilteredBooks(filter string) ([]models.Book, error) {
-	query := "SELECT id, title, author, published_year, status FROM books WHERE status = ?"
-	rows, err := r.db.Query(query, filter)
+func (r *BookRepository) GetAllBooks() ([]models.Book, error) {
+	return r.queryBooks("SELECT " + bookColumns + " FROM books")
+}
+
+// FindBooks returns the books matching a filter expression (see package
+// filter). An empty expression returns every book.
+func (r *BookRepository) FindBooks(where string) ([]models.Book, error) {
+	cond, args, err := filter.Compile(where)
 	if err != nil {
-		return nil, err
+		return nil, withKind(ErrValidation, err)
 	}
-	defer rows.Close()
+	if cond == "" {
+		return r.GetAllBooks()
+	}
+	return r.queryBooks("SELECT "+bookColumns+" FROM books WHERE "+cond, args...)
+}
 
-	var books []models.Book
-	for rows.Next() {
-		var b models.Book
-		err := rows.Scan(&b.ID, &b.Title, &b.Author, &b.PublishedYear, &b.Status)
+func (r *BookRepository) GetBookByID(id int) (models.Book, error) {
+	return getBook(r.db, id)
+}
+
+// AddBook stores a new book, linking it to the author record its author
+// name resolves to, and returns its ID. A book without status is unread.
+// Invalid books are rejected with ErrValidation wrapping validation.Errors.
+func (r *BookRepository) AddBook(book models.Book) (int, error) {
+	if book.Status == "" {
+		book.Status = "unread"
+	}
+	if err := validation.Book(book); err != nil {
+		return 0, withKind(ErrValidation, err)
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	authorID, err := resolveAuthor(tx, book.Author)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO books (title, author, author_id, published_year, status,
+		publisher, language, original_language, original_title,
+		location, format, purchase_date, purchase_price, currency, estimated_value, notes, isbn,
+		pages, read_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, book.Title, book.Author, nullInt(authorID), book.PublishedYear, book.Status,
+		book.Publisher, book.Language, book.OriginalLanguage, book.OriginalTitle,
+		book.Location, book.Format, book.PurchaseDate, book.PurchasePrice, book.Currency, book.EstimatedValue, book.Notes, book.ISBN,
+		book.Pages, book.ReadDate)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), tx.Commit()
+}
+
+// UpdateBook overwrites every stored field of the book with the given ID,
+// after validating them like AddBook.
+func (r *BookRepository) UpdateBook(book models.Book) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateBook(tx, book); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateBooks applies change to each of the books with the given IDs and
+// stores the results, all or none: when a book is missing, change fails or
+// a changed book is invalid, no book is updated. Errors name the book.
+func (r *BookRepository) UpdateBooks(ids []int, change func(book *models.Book) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		book, err := getBook(tx, id)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := change(&book); err != nil {
+			return i18n.Errorf("book with ID %d: %w", id, err)
+		}
+		if err := updateBook(tx, book); err != nil {
+			return i18n.Errorf("book with ID %d: %w", id, err)
 		}
-		books = append(books, b)
 	}
-	return books, nil
+	return tx.Commit()
 }
 
-func (r *BookRepository) AddBook(book models.Book) error {
-	query := `INSERT INTO books (title, author, published_year, status) VALUES (?, ?, ?, ?)`
-	_, err := r.db.Exec(query, book.Title, book.Author, book.PublishedYear, book.Status)
-	return err
+func getBook(q querier, id int) (models.Book, error) {
+	b, err := scanBook(q.QueryRow("SELECT "+bookColumns+" FROM books WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return b, errorf(ErrNotFound, "book with ID %d not found", id)
+	}
+	return b, err
 }
 
-func (r *BookRepository) UpdateStatusBook(id int) error {
-	query := `UPDATE books SET status = 'read' WHERE id = ?`
-	result, err := r.db.Exec(query, id)
+func updateBook(q querier, book models.Book) error {
+	if err := validation.Book(book); err != nil {
+		return withKind(ErrValidation, err)
+	}
+	if err := dateReading(q, &book); err != nil {
+		return err
+	}
+	authorID, err := resolveAuthor(q, book.Author)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE books SET title = ?, author = ?, author_id = ?, published_year = ?, status = ?,
+		publisher = ?, language = ?, original_language = ?, original_title = ?,
+		location = ?, format = ?, purchase_date = ?, purchase_price = ?, currency = ?, estimated_value = ?, notes = ?, isbn = ?,
+		pages = ?, read_date = ?
+		WHERE id = ?`
+	result, err := q.Exec(query, book.Title, book.Author, nullInt(authorID), book.PublishedYear, book.Status,
+		book.Publisher, book.Language, book.OriginalLanguage, book.OriginalTitle,
+		book.Location, book.Format, book.PurchaseDate, book.PurchasePrice, book.Currency, book.EstimatedValue, book.Notes, book.ISBN,
+		book.Pages, book.ReadDate, book.ID)
 	if err != nil {
 		return err
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("book with ID %d not found", id)
+		return errorf(ErrNotFound, "book with ID %d not found", book.ID)
 	}
 	return nil
 }
 
-func (r *BookRepository) DeleteBook(id int) error {
-	query := `DELETE FROM books WHERE id = ?`
-	result, err := r.db.Exec(query, id)
+// dateReading gives a book that is becoming read today as its read date,
+// unless the change sets another one.
+func dateReading(q querier, book *models.Book) error {
+	if book.Status != "read" {
+		return nil
+	}
+	var status sql.NullString
+	var readDate string
+	err := q.QueryRow("SELECT status, read_date FROM books WHERE id = ?", book.ID).Scan(&status, &readDate)
+	if err == sql.ErrNoRows {
+		return errorf(ErrNotFound, "book with ID %d not found", book.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if status.String != "read" && book.ReadDate == readDate {
+		book.ReadDate = today()
+	}
+	return nil
+}
+
+func today() string {
+	return time.Now().Format(time.DateOnly)
+}
+
+func (r *BookRepository) SetCover(id int, cover string) error {
+	query := `UPDATE books SET cover = ? WHERE id = ?`
+	result, err := r.db.Exec(query, cover, id)
 	if err != nil {
 		return err
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("book with ID %d not found", id)
+		return errorf(ErrNotFound, "book with ID %d not found", id)
 	}
-
 	return nil
 }
+
+func (r *BookRepository) UpdateStatusBook(id int) error {
+	return r.MarkAsRead([]int{id})
+}
+
+// MarkAsRead marks the books with the given IDs as read, all or none.
+func (r *BookRepository) MarkAsRead(ids []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		result, err := tx.Exec(`UPDATE books SET status = 'read',
+			read_date = CASE WHEN status IS 'read' THEN read_date ELSE ? END
+			WHERE id = ?`, today(), id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			return errorf(ErrNotFound, "book with ID %d not found", id)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *BookRepository) DeleteBook(id int) error {
+	return r.DeleteBooks([]int{id})
+}
+
+// DeleteBooks deletes the books with the given IDs together with their
+// relations and quotes, all or none.
+func (r *BookRepository) DeleteBooks(ids []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		result, err := tx.Exec(`DELETE FROM books WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			return errorf(ErrNotFound, "book with ID %d not found", id)
+		}
+
+		_, err = tx.Exec(`DELETE FROM book_relations WHERE book_id = ? OR related_id = ?`, id, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM quotes WHERE book_id = ?`, id)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
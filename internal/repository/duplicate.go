@@ -0,0 +1,373 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/fuzzy"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/names"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/translit"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/validation"
+)
+
+// duplicateScore is the lowest fuzzy score at which two titles or author
+// names are taken for spellings of the same one.
+const duplicateScore = 0.8
+
+// articles are left out when comparing titles: "Hobbit" is "The Hobbit".
+var articles = map[string]bool{"the": true, "a": true, "an": true}
+
+// Duplicate is a stored book that is likely the same as another one.
+type Duplicate struct {
+	models.Book
+	Reason string `json:"reason"` // "same ISBN" or "similar title and author"
+}
+
+// duplicateReason tells why books a and b are likely one book entered
+// twice, or returns "" when they are not. Books with different ISBNs, in
+// different languages or of different originals are different editions,
+// not duplicates, and neither is a translation of its original.
+func duplicateReason(a, b models.Book) string {
+	if a.ISBN != "" && b.ISBN != "" {
+		if isbn13(a.ISBN) == isbn13(b.ISBN) {
+			return "same ISBN"
+		}
+		return ""
+	}
+	if bothDiffer(a.Language, b.Language) || bothDiffer(a.OriginalLanguage, b.OriginalLanguage) {
+		return ""
+	}
+	if a.OriginalTitle != "" && b.OriginalTitle != "" && !similarTitles(a.OriginalTitle, b.OriginalTitle) {
+		return ""
+	}
+	if translates(a, b) || translates(b, a) {
+		return ""
+	}
+	sameAuthor := a.AuthorID != 0 && a.AuthorID == b.AuthorID || similarNames(a.Author, b.Author)
+	if sameAuthor && similarTitles(a.Title, b.Title) {
+		return "similar title and author"
+	}
+	return ""
+}
+
+// bothDiffer reports whether language tags a and b are both known and
+// different.
+func bothDiffer(a, b string) bool {
+	return a != "" && b != "" && !strings.EqualFold(a, b)
+}
+
+// translates reports whether book t is marked as a translation of book o:
+// t is a translation from the language of o or of a work titled like o.
+func translates(t, o models.Book) bool {
+	if !t.IsTranslation() {
+		return false
+	}
+	return strings.EqualFold(t.OriginalLanguage, o.Language) || similarTitles(t.OriginalTitle, o.Title)
+}
+
+// similarTitles reports whether a and b are spellings of the same title, in
+// either script and with a few typos. Every word must match: "Dune" is not
+// "Dune Messiah".
+func similarTitles(a, b string) bool {
+	ka, kb := titleKey(a), titleKey(b)
+	if ka == "" || kb == "" {
+		return false
+	}
+	ab, _ := fuzzy.Match(ka, kb)
+	ba, _ := fuzzy.Match(kb, ka)
+	return min(ab, ba) >= duplicateScore
+}
+
+func titleKey(title string) string {
+	words := strings.Fields(translit.Key(title))
+	if len(words) > 1 && articles[words[0]] {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// similarNames reports whether a and b likely name the same author: one is
+// a spelling of the other or of a part of it, like "Tolkien" of
+// "J. R. R. Tolkien".
+func similarNames(a, b string) bool {
+	ka, kb := translit.Key(a), translit.Key(b)
+	if ka == "" || kb == "" {
+		return false
+	}
+	ab, _ := fuzzy.Match(ka, kb)
+	ba, _ := fuzzy.Match(kb, ka)
+	return max(ab, ba) >= duplicateScore
+}
+
+// isbn13 returns a valid ISBN-10 as the ISBN-13 of the same book, so both
+// forms compare equal. Other ISBNs are returned unchanged.
+func isbn13(isbn string) string {
+	isbn = validation.NormalizeISBN(isbn)
+	if len(isbn) != 10 || validation.ISBN(isbn) != nil {
+		return isbn
+	}
+	digits := "978" + isbn[:9]
+	sum := 0
+	for i, c := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(c-'0')
+	}
+	return digits + string(rune('0'+(10-sum%10)%10))
+}
+
+// FindDuplicates returns the stored books that book, which need not be
+// stored yet, likely duplicates.
+func (r *BookRepository) FindDuplicates(book models.Book) ([]Duplicate, error) {
+	if book.AuthorID == 0 {
+		err := r.db.QueryRow("SELECT author_id FROM author_aliases WHERE alias_key = ?", names.Key(book.Author)).Scan(&book.AuthorID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+	books, err := r.GetAllBooks()
+	if err != nil {
+		return nil, err
+	}
+	translations, err := translationPairs(r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var dups []Duplicate
+	for _, b := range books {
+		if b.ID == book.ID || translations[[2]int{book.ID, b.ID}] {
+			continue
+		}
+		if reason := duplicateReason(book, b); reason != "" {
+			dups = append(dups, Duplicate{b, reason})
+		}
+	}
+	return dups, nil
+}
+
+// DuplicateGroups returns the groups of stored books that are likely the
+// same book entered more than once, each ordered by ID. A book is in a
+// group when it duplicates at least one other book of the group; its
+// Reason is the one found for the first such book.
+func (r *BookRepository) DuplicateGroups() ([][]Duplicate, error) {
+	books, err := r.GetAllBooks()
+	if err != nil {
+		return nil, err
+	}
+	translations, err := translationPairs(r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// parent links the books found to be duplicates into trees, one per
+	// group; the root of a tree is the first book of its group.
+	parent := make([]int, len(books))
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := make([]string, len(books))
+	for i := range books {
+		parent[i] = i
+		for j := range i {
+			if translations[[2]int{books[i].ID, books[j].ID}] {
+				continue
+			}
+			reason := duplicateReason(books[i], books[j])
+			if reason == "" {
+				continue
+			}
+			ri, rj := root(i), root(j)
+			parent[max(ri, rj)] = min(ri, rj)
+			for _, k := range []int{i, j} {
+				if reasons[k] == "" {
+					reasons[k] = reason
+				}
+			}
+		}
+	}
+
+	var groups [][]Duplicate
+	index := map[int]int{} // first book of a group to its index in groups
+	for i, b := range books {
+		if reasons[i] == "" {
+			continue
+		}
+		g, ok := index[root(i)]
+		if !ok {
+			g = len(groups)
+			index[root(i)] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], Duplicate{b, reasons[i]})
+	}
+	return groups, nil
+}
+
+// translationPairs returns the pairs of book IDs related as a translation
+// and its original, in both orders.
+func translationPairs(q querier) (map[[2]int]bool, error) {
+	rows, err := q.Query("SELECT book_id, related_id FROM book_relations WHERE type = ?", models.TranslationOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := make(map[[2]int]bool)
+	for rows.Next() {
+		var a, b int
+		if err := rows.Scan(&a, &b); err != nil {
+			return nil, err
+		}
+		pairs[[2]int{a, b}], pairs[[2]int{b, a}] = true, true
+	}
+	return pairs, rows.Err()
+}
+
+// MergeBooks folds the books dupIDs into keepID, all or none: quotes and
+// relations move over, fields missing on keepID are taken from the
+// duplicates, notes are joined, a book read as any of them is read, and
+// the duplicates are deleted.
+func (r *BookRepository) MergeBooks(keepID int, dupIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, dupID := range dupIDs {
+		if err := mergeBook(tx, keepID, dupID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func mergeBook(q querier, keepID, dupID int) error {
+	if keepID == dupID {
+		return errorf(ErrValidation, "cannot merge book with ID %d into itself", keepID)
+	}
+	keep, err := getBook(q, keepID)
+	if err != nil {
+		return err
+	}
+	dup, err := getBook(q, dupID)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range []struct{ keep, dup *string }{
+		{&keep.Publisher, &dup.Publisher}, {&keep.Language, &dup.Language},
+		{&keep.OriginalLanguage, &dup.OriginalLanguage}, {&keep.OriginalTitle, &dup.OriginalTitle},
+		{&keep.ISBN, &dup.ISBN}, {&keep.Location, &dup.Location}, {&keep.Format, &dup.Format},
+		{&keep.PurchaseDate, &dup.PurchaseDate},
+	} {
+		if strings.TrimSpace(*field.keep) == "" {
+			*field.keep = *field.dup
+		}
+	}
+	if keep.PublishedYear == 0 {
+		keep.PublishedYear = dup.PublishedYear
+	}
+	if keep.Pages == 0 {
+		keep.Pages = dup.Pages
+	}
+	// Amounts only mean something in their currency, so price, value and
+	// currency are taken together, when the kept book has no amounts.
+	hasAmounts := func(b models.Book) bool { return b.PurchasePrice != 0 || b.EstimatedValue != 0 }
+	if !hasAmounts(keep) && (hasAmounts(dup) || keep.Currency == "") {
+		keep.PurchasePrice, keep.EstimatedValue, keep.Currency = dup.PurchasePrice, dup.EstimatedValue, dup.Currency
+	}
+	if dup.Status == "read" {
+		keep.Status = "read"
+	}
+	if dup.ReadDate > keep.ReadDate {
+		keep.ReadDate = dup.ReadDate
+	}
+	keep.Notes = joinNotes(keep.Notes, dup.Notes)
+	if err := updateBook(q, keep); err != nil {
+		return err
+	}
+
+	relations, err := bookRelations(q, dupID)
+	if err != nil {
+		return err
+	}
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{"UPDATE books SET cover = ? WHERE id = ? AND cover = ''", []any{dup.Cover, keepID}},
+		{"UPDATE quotes SET book_id = ? WHERE book_id = ?", []any{keepID, dupID}},
+		{"DELETE FROM book_relations WHERE book_id = ? OR related_id = ?", []any{dupID, dupID}},
+	}
+	for _, st := range statements {
+		if _, err := q.Exec(st.query, st.args...); err != nil {
+			return err
+		}
+	}
+
+	// The relations of the duplicate move to the kept book with the checks
+	// of AddRelation; those that would relate the book to itself or close a
+	// cycle are dropped.
+	for _, rel := range relations {
+		for _, id := range []*int{&rel.bookID, &rel.relatedID} {
+			if *id == dupID {
+				*id = keepID
+			}
+		}
+		err := addRelation(q, rel.bookID, rel.typ, rel.relatedID)
+		if err != nil && !errors.Is(err, ErrValidation) && !errors.Is(err, ErrConflict) {
+			return err
+		}
+	}
+
+	_, err = q.Exec("DELETE FROM books WHERE id = ?", dupID)
+	return err
+}
+
+// storedRelation is a row of book_relations.
+type storedRelation struct {
+	bookID    int
+	typ       models.RelationType
+	relatedID int
+}
+
+// bookRelations returns the relations recorded on book id or pointing at it.
+func bookRelations(q querier, id int) ([]storedRelation, error) {
+	rows, err := q.Query("SELECT book_id, type, related_id FROM book_relations WHERE book_id = ? OR related_id = ?", id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relations []storedRelation
+	for rows.Next() {
+		var rel storedRelation
+		if err := rows.Scan(&rel.bookID, &rel.typ, &rel.relatedID); err != nil {
+			return nil, err
+		}
+		relations = append(relations, rel)
+	}
+	return relations, rows.Err()
+}
+
+// joinNotes returns the notes of two books as one text, each kept once.
+func joinNotes(a, b string) string {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	switch {
+	case b == "" || strings.Contains(a, b):
+		return a
+	case a == "" || strings.Contains(b, a):
+		return b
+	}
+	return a + "\n\n" + b
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+// Kinds of errors returned by the repository, to be tested with errors.Is.
+// Other errors are failures of the database itself.
+var (
+	ErrNotFound   = errors.New("not found")          // a book, author, quote or relation does not exist
+	ErrValidation = errors.New("invalid input")      // invalid data or query; see also validation.Errors
+	ErrConflict   = errors.New("conflicting change") // the change contradicts data already stored
+)
+
+// kindError attaches one of the kinds above to an error, keeping its message.
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
+
+func withKind(kind, err error) error {
+	return &kindError{err: err, kind: kind}
+}
+
+func errorf(kind error, format string, args ...any) error {
+	return withKind(kind, i18n.Errorf(format, args...))
+}
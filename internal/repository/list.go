@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/filter"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+)
+
+type ListOptions struct {
+	Where  string   // filter expression, see package filter
+	And    []string // more filter expressions the books must also match
+	Sort   string   // sort specification, e.g. "title,-year"
+	Limit  int      // page size, 0 for no limit
+	Offset int      // number of books to skip
+	After  string   // cursor from BookPage.Next; takes precedence over Offset
+}
+
+type BookPage struct {
+	Books  []models.Book
+	Total  int    // number of books matching the filter
+	Offset int    // position of the first book of the page in the full result
+	Next   string // cursor of the following page, empty on the last page
+}
+
+// cursor identifies the last row of a page by its sort values.
+type cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+// ListBooks returns one page of the books matching opts. Pages are
+// located with keyset pagination, seeking to the sort values of the row
+// preceding the page. With opts.After those values come from the cursor,
+// so deep pages cost about the same as the first one. With opts.Offset
+// SQLite still steps over the skipped rows to find them, though only
+// reading their sort columns rather than full book records.
+func (r *BookRepository) ListBooks(opts ListOptions) (BookPage, error) {
+	var page BookPage
+
+	cond, args, err := compileAll(append([]string{opts.Where}, opts.And...))
+	if err != nil {
+		return page, err
+	}
+	order, err := filter.ParseSort(opts.Sort)
+	if err != nil {
+		return page, withKind(ErrValidation, err)
+	}
+
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM books WHERE "+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	// Sort values of the row right before the requested page, if any.
+	var boundary []any
+	switch {
+	case opts.After != "":
+		boundary, err = decodeCursor(opts.After, order)
+		if err != nil {
+			return page, err
+		}
+		after, afterArgs := order.After(boundary)
+		err = r.db.QueryRow("SELECT COUNT(*) FROM books WHERE "+cond+" AND NOT "+after,
+			append(append([]any{}, args...), afterArgs...)...).Scan(&page.Offset)
+		if err != nil {
+			return page, err
+		}
+	case opts.Offset > 0:
+		page.Offset = opts.Offset
+		query := fmt.Sprintf("SELECT %s FROM books WHERE %s ORDER BY %s LIMIT 1 OFFSET ?",
+			order.Select(), cond, order.OrderBy())
+		boundary, err = r.sortValues(query, order, append(append([]any{}, args...), opts.Offset-1)...)
+		if err == sql.ErrNoRows {
+			return page, nil // past the end
+		}
+		if err != nil {
+			return page, err
+		}
+	}
+
+	query := "SELECT " + bookColumns + " FROM books WHERE " + cond
+	queryArgs := append([]any{}, args...)
+	if boundary != nil {
+		after, afterArgs := order.After(boundary)
+		query += " AND " + after
+		queryArgs = append(queryArgs, afterArgs...)
+	}
+	query += " ORDER BY " + order.OrderBy()
+	if opts.Limit > 0 {
+		query += " LIMIT ?"
+		queryArgs = append(queryArgs, opts.Limit)
+	}
+
+	page.Books, err = r.queryBooks(query, queryArgs...)
+	if err != nil {
+		return page, err
+	}
+
+	if opts.Limit > 0 && len(page.Books) > 0 && page.Offset+len(page.Books) < page.Total {
+		last := page.Books[len(page.Books)-1]
+		values, err := r.sortValues(fmt.Sprintf("SELECT %s FROM books WHERE id = ?", order.Select()), order, last.ID)
+		if err != nil {
+			return page, err
+		}
+		page.Next, err = encodeCursor(order, values)
+		if err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
+
+// compileAll compiles filter expressions into one condition matched by the
+// books matching all of them. Each expression is compiled on its own, so
+// none can change how another one reads.
+func compileAll(exprs []string) (string, []any, error) {
+	var conds []string
+	var args []any
+	for _, expr := range exprs {
+		cond, exprArgs, err := filter.Compile(expr)
+		if err != nil {
+			return "", nil, withKind(ErrValidation, err)
+		}
+		if cond != "" {
+			conds = append(conds, "("+cond+")")
+			args = append(args, exprArgs...)
+		}
+	}
+	if len(conds) == 0 {
+		return "1 = 1", nil, nil
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+func (r *BookRepository) sortValues(query string, order filter.Order, args ...any) ([]any, error) {
+	values := make([]any, len(order))
+	ptrs := make([]any, len(order))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := r.db.QueryRow(query, args...).Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		if b, ok := v.([]byte); ok {
+			values[i] = string(b)
+		}
+	}
+	return values, nil
+}
+
+func encodeCursor(order filter.Order, values []any) (string, error) {
+	data, err := json.Marshal(cursor{Sort: order.String(), Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(s string, order filter.Order) ([]any, error) {
+	invalid := errorf(ErrValidation, "invalid page cursor %q", s)
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, invalid
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var c cursor
+	if err := dec.Decode(&c); err != nil {
+		return nil, invalid
+	}
+	if c.Sort != order.String() {
+		return nil, errorf(ErrValidation, "page cursor was created for --sort %s, not %s", c.Sort, order)
+	}
+	if len(c.Values) != len(order) {
+		return nil, invalid
+	}
+
+	for i, v := range c.Values {
+		if n, ok := v.(json.Number); ok {
+			if iv, err := n.Int64(); err == nil {
+				c.Values[i] = iv
+				continue
+			}
+			c.Values[i], _ = n.Float64()
+		}
+	}
+	return c.Values, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+)
+
+// AddQuote stores a quote from a book and returns its ID.
+func (r *BookRepository) AddQuote(quote models.Quote) (int, error) {
+	var exists int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM books WHERE id = ?", quote.BookID).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists == 0 {
+		return 0, errorf(ErrNotFound, "book with ID %d not found", quote.BookID)
+	}
+
+	result, err := r.db.Exec(`INSERT INTO quotes (book_id, text, page) VALUES (?, ?, ?)`,
+		quote.BookID, quote.Text, nullInt(quote.Page))
+	if err != nil {
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), nil
+}
+
+// GetQuotes returns the quotes from a book in page order.
+func (r *BookRepository) GetQuotes(bookID int) ([]models.Quote, error) {
+	rows, err := r.db.Query(`SELECT id, book_id, text, page FROM quotes
+		WHERE book_id = ? ORDER BY COALESCE(page, 0), id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quotes []models.Quote
+	for rows.Next() {
+		var q models.Quote
+		var page sql.NullInt64
+		if err := rows.Scan(&q.ID, &q.BookID, &q.Text, &page); err != nil {
+			return nil, err
+		}
+		q.Page = int(page.Int64)
+		quotes = append(quotes, q)
+	}
+	return quotes, rows.Err()
+}
+
+func (r *BookRepository) DeleteQuote(id int) error {
+	result, err := r.db.Exec(`DELETE FROM quotes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errorf(ErrNotFound, "quote with ID %d not found", id)
+	}
+	return nil
+}
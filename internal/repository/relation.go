@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+)
+
+// AddRelation records that book bookID relates to book relatedID. Relations
+// that would close a cycle of the same type (a book containing itself,
+// directly or through other books) are rejected.
+func (r *BookRepository) AddRelation(bookID int, typ models.RelationType, relatedID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addRelation(tx, bookID, typ, relatedID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addRelation records a relation within a transaction, see AddRelation.
+func addRelation(q querier, bookID int, typ models.RelationType, relatedID int) error {
+	if bookID == relatedID {
+		return errorf(ErrValidation, "book with ID %d cannot be related to itself", bookID)
+	}
+
+	for _, id := range []int{bookID, relatedID} {
+		var exists int
+		if err := q.QueryRow("SELECT COUNT(*) FROM books WHERE id = ?", id).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return errorf(ErrNotFound, "book with ID %d not found", id)
+		}
+	}
+
+	if typ.Symmetric() {
+		var exists int
+		err := q.QueryRow(`SELECT COUNT(*) FROM book_relations
+			WHERE type = ? AND book_id = ? AND related_id = ?`, typ, relatedID, bookID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			return nil
+		}
+	} else {
+		// Walk the relations of this type starting at relatedID; reaching
+		// bookID means the new edge would close a cycle.
+		var cycle int
+		err := q.QueryRow(`
+			WITH RECURSIVE reachable(id) AS (
+				SELECT ?
+				UNION
+				SELECT r.related_id FROM book_relations r
+				JOIN reachable ON r.book_id = reachable.id
+				WHERE r.type = ?
+			)
+			SELECT COUNT(*) FROM reachable WHERE id = ?`, relatedID, typ, bookID).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle > 0 {
+			return errorf(ErrConflict, "book %d %s book %d would create a cycle", bookID, typ, relatedID)
+		}
+	}
+
+	_, err := q.Exec(`INSERT OR IGNORE INTO book_relations (book_id, type, related_id) VALUES (?, ?, ?)`,
+		bookID, typ, relatedID)
+	return err
+}
+
+func (r *BookRepository) RemoveRelation(bookID int, typ models.RelationType, relatedID int) error {
+	query := `DELETE FROM book_relations WHERE type = ? AND
+		((book_id = ? AND related_id = ?) OR (? AND book_id = ? AND related_id = ?))`
+	result, err := r.db.Exec(query, typ, bookID, relatedID, typ.Symmetric(), relatedID, bookID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errorf(ErrNotFound, "book %d is not %s book %d", bookID, typ, relatedID)
+	}
+	return nil
+}
+
+// GetRelations returns both the relations recorded on the book and those
+// recorded on other books pointing at it.
+func (r *BookRepository) GetRelations(id int) ([]models.Relation, error) {
+	rows, err := r.db.Query(`
+		SELECT r.type, b.id, b.title, 0 FROM book_relations r
+		JOIN books b ON b.id = r.related_id
+		WHERE r.book_id = ?
+		UNION ALL
+		SELECT r.type, b.id, b.title, 1 FROM book_relations r
+		JOIN books b ON b.id = r.book_id
+		WHERE r.related_id = ?
+		ORDER BY 1, 2`, id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relations []models.Relation
+	for rows.Next() {
+		var rel models.Relation
+		if err := rows.Scan(&rel.Type, &rel.RelatedID, &rel.RelatedTitle, &rel.Incoming); err != nil {
+			return nil, err
+		}
+		relations = append(relations, rel)
+	}
+	return relations, rows.Err()
+}
@@ -0,0 +1,506 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/fuzzy"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/translit"
+)
+
+// Snippets mark the matched words with these control characters; callers
+// replace them with whatever highlighting suits their output.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// searchColumns are the indexed columns of books_fts and
+// book_search_documents, in order, with their BM25 weights.
+var searchColumns = []struct {
+	name   string
+	weight float64
+}{
+	{"title", 10}, {"original_title", 5}, {"author", 5}, {"notes", 1}, {"quotes", 1},
+}
+
+// keysWeight is the BM25 weight of the keys column that follows
+// searchColumns, holding the transliteration keys of title, original title
+// and author.
+const keysWeight = 5
+
+// SearchResult is a book found by Search.
+type SearchResult struct {
+	models.Book
+	Rank    float64 `json:"rank"`    // lower is more relevant: BM25 score, or the negated fuzzy.Match score
+	Snippet string  `json:"snippet"` // matching text, hits between HighlightStart and HighlightEnd
+}
+
+// Highlight returns the snippet with every hit passed through mark.
+func (r SearchResult) Highlight(mark func(hit string) string) string {
+	var sb strings.Builder
+	snippet := r.Snippet
+	for {
+		start := strings.Index(snippet, HighlightStart)
+		end := strings.Index(snippet, HighlightEnd)
+		if start < 0 || end < start {
+			break
+		}
+		sb.WriteString(snippet[:start])
+		sb.WriteString(mark(snippet[start+len(HighlightStart) : end]))
+		snippet = snippet[end+len(HighlightEnd):]
+	}
+	sb.WriteString(snippet)
+	return sb.String()
+}
+
+// Search finds books by title, original title, author (including aliases),
+// notes and quotes, best matches first. The query is a list of words, all
+// of which must match; "quoted phrases" match consecutive words, a trailing
+// * matches a prefix, OR and NOT combine terms, and column:word restricts a
+// word to one column.
+//
+// Words also match when typed in the other keyboard layout, and titles and
+// authors are found across scripts: "tolstoy" finds "Толстой".
+//
+// Without FTS5 (see db.HasFullTextSearch) the same query is answered with
+// substring matching, ordered by title.
+func (r *BookRepository) Search(query string, limit int) ([]SearchResult, error) {
+	terms, err := parseSearch(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var fts bool
+	if err := r.db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts); err != nil {
+		return nil, err
+	}
+	if !fts {
+		return r.searchLike(terms, limit)
+	}
+
+	weights := make([]string, len(searchColumns), len(searchColumns)+1)
+	snippets := make([]string, len(searchColumns))
+	var args []any
+	for i, c := range searchColumns {
+		weights[i] = fmt.Sprint(c.weight)
+		// One snippet per column: with -1, FTS5 could pick the keys column.
+		snippets[i] = fmt.Sprintf("snippet(books_fts, %d, ?, ?, '…', 12)", i)
+		args = append(args, HighlightStart, HighlightEnd)
+	}
+	weights = append(weights, fmt.Sprint(keysWeight))
+	rows, err := r.db.Query(`SELECT `+bookColumns+`, hit.* FROM books
+		JOIN (SELECT rowid AS match_id, bm25(books_fts, `+strings.Join(weights, ", ")+`) AS score,
+			`+strings.Join(snippets, ", ")+`
+			FROM books_fts WHERE books_fts MATCH ?) hit ON match_id = id
+		ORDER BY score, id LIMIT ?`,
+		append(args, ftsQuery(terms), limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var res SearchResult
+		var matchID int
+		snippets := make([]string, len(searchColumns))
+		res.Book, err = scanBook(scanFunc(func(dest ...any) error {
+			dest = append(dest, &matchID, &res.Rank)
+			for i := range snippets {
+				dest = append(dest, &snippets[i])
+			}
+			return rows.Scan(dest...)
+		}))
+		if err != nil {
+			return nil, err
+		}
+		// Like snippet(-1): the column with the most hits.
+		best := 0
+		for _, snippet := range snippets {
+			if n := strings.Count(snippet, HighlightStart); n > best {
+				best, res.Snippet = n, snippet
+			}
+		}
+		if best == 0 {
+			res.Snippet = translitSnippet([]string{res.Title, res.OriginalTitle, res.Author}, terms)
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+// FuzzySearch finds books whose title, original title or author (including
+// aliases) resembles the query despite typos, in either keyboard layout or
+// script, best matches first. Notes and
+// quotes are left out: in long texts some word is always similar enough.
+func (r *BookRepository) FuzzySearch(query string, limit int) ([]SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errorf(ErrValidation, "empty search query")
+	}
+	queries := []string{query}
+	if swapped := translit.SwapLayout(query); swapped != query {
+		queries = append(queries, swapped)
+	}
+	key := translit.Key(query)
+
+	rows, err := r.db.Query(`SELECT ` + bookColumns + `, doc.* FROM books
+		JOIN (SELECT id AS doc_id, title AS doc_title, original_title AS doc_original_title,
+			author AS doc_author FROM book_search_documents) doc ON doc_id = id
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var res SearchResult
+		var docID int
+		texts := make([]string, 3)
+		res.Book, err = scanBook(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &docID, &texts[0], &texts[1], &texts[2])...)
+		}))
+		if err != nil {
+			return nil, err
+		}
+
+		best := 0.0
+		for _, text := range texts {
+			for _, q := range queries {
+				score, hits := fuzzy.Match(q, text)
+				if score > best {
+					best = score
+					res.Snippet = markHits(text, hits)
+				}
+			}
+			// Across scripts, by comparing the transliteration keys.
+			if score, _ := fuzzy.Match(key, translit.Key(text)); score > best {
+				best = score
+				res.Snippet = markHits(text, translit.Hits(text, query))
+			}
+		}
+		if best >= fuzzy.MinScore {
+			res.Rank = -best
+			results = append(results, res)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank < results[j].Rank
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// markHits encloses the given byte ranges of text in highlight markers.
+func markHits(text string, hits [][2]int) string {
+	var sb strings.Builder
+	last := 0
+	for _, h := range hits {
+		sb.WriteString(text[last:h[0]])
+		sb.WriteString(HighlightStart + text[h[0]:h[1]] + HighlightEnd)
+		last = h[1]
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// scanFunc adapts a function to rowScanner, for rows with extra columns
+// after the book.
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
+
+// searchTerm is a word, phrase or operator of a search query.
+type searchTerm struct {
+	op     string // "OR" or "NOT" for operators, empty for words and phrases
+	column string // column the term is restricted to, if any
+	text   string
+	prefix bool
+}
+
+func parseSearch(query string) ([]searchTerm, error) {
+	var terms []searchTerm
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+
+		var t searchTerm
+		start := i
+		for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '"' {
+			i++
+		}
+		word := string(rs[start:i])
+		if name, rest, ok := strings.Cut(word, ":"); ok && isSearchColumn(name) {
+			t.column = strings.ToLower(name)
+			word = rest
+		}
+
+		if word == "" && i < len(rs) && rs[i] == '"' {
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			if end == len(rs) {
+				return nil, errorf(ErrValidation, "unterminated phrase in search query")
+			}
+			t.text = string(rs[i+1 : end])
+			i = end + 1
+			if i < len(rs) && rs[i] == '*' {
+				t.prefix = true
+				i++
+			}
+		} else {
+			if t.column == "" && (word == "OR" || word == "NOT" || word == "AND") {
+				if word != "AND" {
+					terms = append(terms, searchTerm{op: word})
+				}
+				continue
+			}
+			t.text, t.prefix = strings.CutSuffix(word, "*")
+		}
+		if strings.TrimSpace(t.text) == "" {
+			continue
+		}
+		terms = append(terms, t)
+	}
+
+	if len(terms) == 0 {
+		return nil, errorf(ErrValidation, "empty search query")
+	}
+	for i, t := range terms {
+		if t.op != "" && (i == 0 || i == len(terms)-1 || terms[i-1].op != "" || terms[i+1].op != "") {
+			return nil, errorf(ErrValidation, "%s must stand between two search terms", t.op)
+		}
+	}
+	return terms, nil
+}
+
+func isSearchColumn(name string) bool {
+	for _, c := range searchColumns {
+		if strings.EqualFold(c.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// variants returns the texts a term matches: as typed, and as typed in the
+// other keyboard layout.
+func (t searchTerm) variants() []string {
+	if swapped := translit.SwapLayout(t.text); swapped != t.text {
+		return []string{t.text, swapped}
+	}
+	return []string{t.text}
+}
+
+// keys returns the transliteration keys a term matches. Terms restricted to
+// a column have none, as the keys of all columns are kept together.
+func (t searchTerm) keys() []string {
+	if t.column != "" {
+		return nil
+	}
+	var keys []string
+	for _, v := range t.variants() {
+		if k := translit.Key(v); k != "" && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// ftsQuery writes terms in FTS5 query syntax. Every word is quoted, so
+// punctuation in titles cannot be mistaken for query syntax.
+func ftsQuery(terms []searchTerm) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		if t.op != "" {
+			parts[i] = t.op
+			continue
+		}
+		var alts []string
+		for _, v := range t.variants() {
+			alts = append(alts, ftsTerm(t.column, v, t.prefix))
+		}
+		// Keys always match as prefixes: transliterations often differ
+		// in the ending ("Karamazov", "Карамазовы").
+		for _, k := range t.keys() {
+			alts = append(alts, ftsTerm("keys", k, true))
+		}
+		parts[i] = strings.Join(alts, " OR ")
+		if len(alts) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func ftsTerm(column, text string, prefix bool) string {
+	s := `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+	if prefix {
+		s += "*"
+	}
+	if column != "" {
+		s = column + " : " + s
+	}
+	return s
+}
+
+// searchLike answers a search with LIKE over book_search_documents. Like in
+// FTS5, NOT binds tighter than the implicit AND, which binds tighter than OR.
+func (r *BookRepository) searchLike(terms []searchTerm, limit int) ([]SearchResult, error) {
+	var groups, group []string
+	var args []any
+	negate := false
+	for _, t := range terms {
+		switch t.op {
+		case "OR":
+			groups = append(groups, strings.Join(group, " AND "))
+			group = nil
+			continue
+		case "NOT":
+			negate = true
+			continue
+		}
+
+		columns := []string{t.column}
+		if t.column == "" {
+			columns = columns[:0]
+			for _, c := range searchColumns {
+				columns = append(columns, c.name)
+			}
+		}
+		var cond []string
+		for _, v := range t.variants() {
+			for _, c := range columns {
+				cond = append(cond, "unicode_fold(d."+c+`) LIKE unicode_fold(?) ESCAPE '\'`)
+				args = append(args, "%"+escapeLike(v)+"%")
+			}
+		}
+		for _, k := range t.keys() {
+			cond = append(cond, `d.keys LIKE ? ESCAPE '\'`)
+			args = append(args, "%"+escapeLike(k)+"%")
+		}
+		expr := "(" + strings.Join(cond, " OR ") + ")"
+		if negate {
+			expr = "NOT " + expr
+			negate = false
+		}
+		group = append(group, expr)
+	}
+	groups = append(groups, strings.Join(group, " AND "))
+
+	columns := make([]string, len(searchColumns))
+	for i, c := range searchColumns {
+		columns[i] = "d." + c.name + " AS doc_" + c.name
+	}
+	args = append(args, limit)
+	rows, err := r.db.Query(`SELECT `+bookColumns+`, doc.* FROM books
+		JOIN (SELECT d.id AS doc_id, `+strings.Join(columns, ", ")+`
+			FROM book_search_documents d
+			WHERE `+strings.Join(groups, " OR ")+`) doc ON doc_id = id
+		ORDER BY title COLLATE UNICODE, id LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var res SearchResult
+		var docID int
+		texts := make([]string, len(searchColumns))
+		res.Book, err = scanBook(scanFunc(func(dest ...any) error {
+			dest = append(dest, &docID)
+			for i := range texts {
+				dest = append(dest, &texts[i])
+			}
+			return rows.Scan(dest...)
+		}))
+		if err != nil {
+			return nil, err
+		}
+		res.Snippet = likeSnippet(texts, terms)
+		if res.Snippet == "" {
+			res.Snippet = translitSnippet(texts[:3], terms)
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// likeSnippet imitates the FTS5 snippet function: a few words around the
+// first hit in the first column containing one.
+func likeSnippet(texts []string, terms []searchTerm) string {
+	const context = 30 // runes shown on each side of the hit
+	for _, text := range texts {
+		lower := strings.ToLower(text)
+		for _, t := range terms {
+			if t.op != "" {
+				continue
+			}
+			at, v := -1, ""
+			for _, v = range t.variants() {
+				if at = strings.Index(lower, strings.ToLower(v)); at >= 0 {
+					break
+				}
+			}
+			// Lowercasing may change byte lengths; only use exact offsets.
+			if at < 0 || len(lower) != len(text) {
+				continue
+			}
+			rs := []rune(text)
+			hit := len([]rune(text[:at]))
+			end := hit + len([]rune(v))
+			from, to := max(hit-context, 0), min(end+context, len(rs))
+
+			var sb strings.Builder
+			if from > 0 {
+				sb.WriteString("…")
+			}
+			sb.WriteString(string(rs[from:hit]))
+			sb.WriteString(HighlightStart + string(rs[hit:end]) + HighlightEnd)
+			sb.WriteString(string(rs[end:to]))
+			if to < len(rs) {
+				sb.WriteString("…")
+			}
+			return sb.String()
+		}
+	}
+	return ""
+}
+
+// translitSnippet marks the words of the first text matching the terms by
+// transliteration key, for books found that way.
+func translitSnippet(texts []string, terms []searchTerm) string {
+	var words []string
+	for i, t := range terms {
+		if t.op == "" && (i == 0 || terms[i-1].op != "NOT") {
+			words = append(words, t.text)
+		}
+	}
+	query := strings.Join(words, " ")
+	for _, text := range texts {
+		if hits := translit.Hits(text, query); len(hits) > 0 {
+			return markHits(text, hits)
+		}
+	}
+	return ""
+}
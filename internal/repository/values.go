@@ -0,0 +1,44 @@
+package repository
+
+import "github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+
+// valueColumns are the fields with a limited set of values worth offering,
+// e.g. for shell completion.
+var valueColumns = map[string]string{
+	"author":            "author",
+	"publisher":         "publisher",
+	"language":          "language",
+	"original_language": "original_language",
+	"location":          "location",
+	"format":            "format",
+	"currency":          "currency",
+}
+
+// FieldValues returns the values a field has in the library, the most
+// common first, and how many books have each.
+func (r *BookRepository) FieldValues(field string) ([]string, []int, error) {
+	column, ok := valueColumns[field]
+	if !ok {
+		return nil, nil, i18n.Errorf("no values are collected for field %q", field)
+	}
+	rows, err := r.db.Query(`SELECT ` + column + `, COUNT(*) FROM books
+		WHERE ` + column + ` != '' GROUP BY ` + column + `
+		ORDER BY COUNT(*) DESC, ` + column + ` COLLATE UNICODE`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	var counts []int
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, nil, err
+		}
+		values = append(values, value)
+		counts = append(counts, count)
+	}
+	return values, counts, rows.Err()
+}
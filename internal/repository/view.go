@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/filter"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+)
+
+// SaveView stores a view, replacing the view of the same name. Its filter
+// expression and sort order are checked as ListBooks would check them.
+func (r *BookRepository) SaveView(view models.View) error {
+	if strings.TrimSpace(view.Name) == "" {
+		return errorf(ErrValidation, "a view needs a name")
+	}
+	if _, _, err := filter.Compile(view.Where); err != nil {
+		return withKind(ErrValidation, err)
+	}
+	if _, err := filter.ParseSort(view.Sort); err != nil {
+		return withKind(ErrValidation, err)
+	}
+
+	_, err := r.db.Exec(`INSERT INTO views (name, filter, sort) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET filter = excluded.filter, sort = excluded.sort`,
+		view.Name, view.Where, view.Sort)
+	return err
+}
+
+// GetViews returns the saved views by name.
+func (r *BookRepository) GetViews() ([]models.View, error) {
+	rows, err := r.db.Query(`SELECT name, filter, sort FROM views ORDER BY name COLLATE UNICODE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []models.View
+	for rows.Next() {
+		var v models.View
+		if err := rows.Scan(&v.Name, &v.Where, &v.Sort); err != nil {
+			return nil, err
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+func (r *BookRepository) GetView(name string) (models.View, error) {
+	v := models.View{Name: name}
+	err := r.db.QueryRow(`SELECT filter, sort FROM views WHERE name = ?`, name).Scan(&v.Where, &v.Sort)
+	if err == sql.ErrNoRows {
+		return v, errorf(ErrNotFound, "view %q not found", name)
+	}
+	return v, err
+}
+
+func (r *BookRepository) DeleteView(name string) error {
+	result, err := r.db.Exec(`DELETE FROM views WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errorf(ErrNotFound, "view %q not found", name)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package translit matches text across the Cyrillic and Latin scripts and
+// across keyboard layouts: "Tolstoy" finds "Толстой", and "Njkcnjq", which is
+// "Толстой" typed with the Latin layout active, finds it too.
+//
+// Transliteration has many conventions ("Dostoevsky", "Dostoyevsky",
+// "Dostojewski"), so rather than converting one script into the other, Key
+// reduces text in either script to a loose Latin spelling on which the
+// common conventions agree.
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	// Ukrainian and Belarusian letters
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "u",
+}
+
+// spellings folds the variants of the transliteration conventions.
+var spellings = strings.NewReplacer(
+	"tch", "ch", "sch", "sh", "kh", "h", "ph", "f", "ck", "k",
+	"tz", "ts", "w", "v", "x", "ks", "y", "i", "j", "i",
+)
+
+// Key returns the transliteration key of s: lower case words separated by
+// single spaces, Cyrillic letters replaced by Latin ones. Two spellings of a
+// name in either script usually share a key, and the key of the start of a
+// word is a prefix of the key of the word.
+func Key(s string) string {
+	keys := make([]string, 0, 4)
+	for _, w := range words(s) {
+		if k := wordKey(w.text); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return strings.Join(keys, " ")
+}
+
+func wordKey(word string) string {
+	var latin strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if s, ok := cyrillic[r]; ok {
+			latin.WriteString(s)
+		} else {
+			latin.WriteRune(r)
+		}
+	}
+	// "Dostoyevsky" and "Pierre" lose their i before e.
+	s := strings.ReplaceAll(spellings.Replace(latin.String()), "ie", "e")
+
+	var sb strings.Builder
+	var last rune
+	for _, r := range s {
+		if r != last { // doubled letters are often dropped
+			sb.WriteRune(r)
+		}
+		last = r
+	}
+	return sb.String()
+}
+
+// A word is a run of letters and digits in a text, with its byte offsets.
+type word struct {
+	text       string
+	start, end int
+}
+
+func words(s string) []word {
+	var ws []word
+	start := -1
+	for i, r := range s {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && start < 0 {
+			start = i
+		}
+		if !inWord && start >= 0 {
+			ws = append(ws, word{s[start:i], start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ws = append(ws, word{s[start:], start, len(s)})
+	}
+	return ws
+}
+
+// Keys of the ЙЦУКЕН layout, in the order of the QWERTY keys they share.
+const (
+	qwerty = "qwertyuiop[]asdfghjkl;'zxcvbnm,.`" + "QWERTYUIOP{}ASDFGHJKL:\"ZXCVBNM<>~"
+	jcuken = "йцукенгшщзхъфывапролджэячсмитьбюё" + "ЙЦУКЕНГШЩЗХЪФЫВАПРОЛДЖЭЯЧСМИТЬБЮЁ"
+)
+
+var layouts = func() map[rune]rune {
+	q, j := []rune(qwerty), []rune(jcuken)
+	m := make(map[rune]rune, 2*len(q))
+	for i := range q {
+		m[q[i]] = j[i]
+		m[j[i]] = q[i]
+	}
+	return m
+}()
+
+// SwapLayout returns s as it would have been typed with the other keyboard
+// layout active: Latin keys become the Russian letters on the same keys and
+// the other way round. Text that uses no such key is returned unchanged.
+func SwapLayout(s string) string {
+	return strings.Map(func(r rune) rune {
+		if swapped, ok := layouts[r]; ok {
+			return swapped
+		}
+		return r
+	}, s)
+}
+
+// Hits returns the byte ranges of the words of text whose key starts with
+// the key of a word of query, or of query typed in the other layout.
+func Hits(text, query string) [][2]int {
+	var keys []string
+	for _, q := range []string{query, SwapLayout(query)} {
+		for _, w := range words(q) {
+			if k := wordKey(w.text); k != "" {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	var hits [][2]int
+	for _, w := range words(text) {
+		k := wordKey(w.text)
+		for _, qk := range keys {
+			if k != "" && strings.HasPrefix(k, qk) {
+				hits = append(hits, [2]int{w.start, w.end})
+				break
+			}
+		}
+	}
+	return hits
+}
@@ -0,0 +1,218 @@
+// Package validation checks books before they are stored. The repository
+// validates every book it writes, so the CLI, the TUI and any other entry
+// point reject the same data with the same messages.
+package validation
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+// A Kind classifies what is wrong with a field.
+type Kind int
+
+const (
+	Empty      Kind = iota + 1 // a required field is missing
+	OutOfRange                 // a number is too small or too large
+	Unknown                    // not one of the allowed values
+	Malformed                  // does not follow the required format
+)
+
+// FieldError reports an invalid value of one field.
+type FieldError struct {
+	Field string // name of the field as in --where expressions, e.g. "year"
+	Kind  Kind
+	Msg   string // what is wrong in the language of messages, e.g. "is required"
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+// Errors are all the problems found in one book, in field order.
+type Errors []*FieldError
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (es Errors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// MinYear is the earliest publication year accepted, early enough for the
+// oldest written works.
+const MinYear = -3000
+
+// Book checks every field of book and returns Errors listing the invalid
+// ones, or nil.
+func Book(book models.Book) error {
+	var errs Errors
+	add := func(err error) {
+		var fe *FieldError
+		if errors.As(err, &fe) {
+			errs = append(errs, fe)
+		}
+	}
+	add(Title(book.Title))
+	add(Author(book.Author))
+	add(Year(book.PublishedYear))
+	add(Status(book.Status))
+	add(ISBN(book.ISBN))
+	add(Pages(book.Pages))
+	add(languageTag("language", book.Language))
+	add(languageTag("original_language", book.OriginalLanguage))
+	add(Format(book.Format))
+	add(date("purchased", book.PurchaseDate))
+	add(date("finished", book.ReadDate))
+	add(currencyCode(book))
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func Title(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return &FieldError{"title", Empty, i18n.T("is required")}
+	}
+	return nil
+}
+
+func Author(author string) error {
+	if strings.TrimSpace(author) == "" {
+		return &FieldError{"author", Empty, i18n.T("is required")}
+	}
+	return nil
+}
+
+// Year requires a year from MinYear to next year, for books announced
+// ahead of publication. There is no year 0, so 0 is a missing year.
+func Year(year int) error {
+	if year == 0 {
+		return &FieldError{"year", Empty, i18n.T("is required")}
+	}
+	maxYear := time.Now().Year() + 1
+	if year < MinYear || year > maxYear {
+		return &FieldError{"year", OutOfRange, i18n.Sprintf("must be between %d and %d", MinYear, maxYear)}
+	}
+	return nil
+}
+
+func Status(status string) error {
+	if !slices.Contains(models.Statuses, status) {
+		return &FieldError{"status", Unknown, i18n.Sprintf("must be one of %s", strings.Join(models.Statuses, ", "))}
+	}
+	return nil
+}
+
+// Pages accepts 0 for an unknown page count.
+func Pages(pages int) error {
+	if pages < 0 {
+		return &FieldError{"pages", OutOfRange, i18n.T("must not be negative")}
+	}
+	return nil
+}
+
+func Format(format string) error {
+	if format != "" && !slices.Contains(models.Formats, format) {
+		return &FieldError{"format", Unknown, i18n.Sprintf("must be one of %s", strings.Join(models.Formats, ", "))}
+	}
+	return nil
+}
+
+// ISBN accepts an empty ISBN or a valid ISBN-10 or ISBN-13 in the form
+// NormalizeISBN returns.
+func ISBN(isbn string) error {
+	if isbn == "" || validISBN(isbn) {
+		return nil
+	}
+	return &FieldError{"isbn", Malformed, i18n.Sprintf("%q is not a valid ISBN-10 or ISBN-13", isbn)}
+}
+
+// NormalizeISBN removes the hyphens and spaces that ISBNs are printed
+// with, and upper-cases the check digit X.
+func NormalizeISBN(isbn string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+}
+
+func validISBN(isbn string) bool {
+	sum := 0
+	switch len(isbn) {
+	case 10:
+		for i, c := range isbn {
+			var digit int
+			switch {
+			case c >= '0' && c <= '9':
+				digit = int(c - '0')
+			case c == 'X' && i == 9:
+				digit = 10
+			default:
+				return false
+			}
+			sum += (10 - i) * digit
+		}
+		return sum%11 == 0
+	case 13:
+		for i, c := range isbn {
+			if c < '0' || c > '9' {
+				return false
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += weight * int(c-'0')
+		}
+		return sum%10 == 0
+	}
+	return false
+}
+
+func languageTag(field, tag string) error {
+	if tag == "" {
+		return nil
+	}
+	if _, err := language.Parse(tag); err != nil {
+		return &FieldError{field, Malformed, i18n.Sprintf("%q is not a BCP-47 language tag, e.g. ru or en-GB", tag)}
+	}
+	return nil
+}
+
+func date(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return &FieldError{field, Malformed, i18n.Sprintf("%q is not a date, expected YYYY-MM-DD", value)}
+	}
+	return nil
+}
+
+func currencyCode(book models.Book) error {
+	if book.Currency == "" {
+		if book.PurchasePrice != 0 || book.EstimatedValue != 0 {
+			return &FieldError{"currency", Empty, i18n.T("is required when a price or value is set")}
+		}
+		return nil
+	}
+	if _, err := currency.ParseISO(book.Currency); err != nil {
+		return &FieldError{"currency", Unknown, i18n.Sprintf("%q is not an ISO 4217 code such as EUR", book.Currency)}
+	}
+	return nil
+}
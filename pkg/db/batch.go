@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/mattn/go-sqlite3"
+)
+
+// A Batch runs the work of several commands in one transaction. While a
+// batch is open, InitDB returns handles on its connection instead of new
+// connections, and the transactions begun on those handles become
+// savepoints inside the batch: they still roll back on their own, but
+// only committing the batch makes their changes permanent.
+//
+// If the process exits before Commit, SQLite rolls the batch back.
+type Batch struct {
+	conn       *sqlite3.SQLiteConn
+	savepoints int
+}
+
+// batch is the open batch, if any.
+var batch *Batch
+
+// BeginBatch migrates the database and starts a batch.
+func BeginBatch() (*Batch, error) {
+	if batch != nil {
+		return nil, i18n.Errorf("a batch is already open")
+	}
+	db, err := InitDB()
+	if err != nil {
+		return nil, err
+	}
+	db.Close()
+
+	conn, err := sqliteDriver.Open(Path)
+	if err != nil {
+		return nil, i18n.Errorf("failed to open database: %v", err)
+	}
+	b := &Batch{conn: conn.(*sqlite3.SQLiteConn)}
+	if err := b.exec("BEGIN IMMEDIATE"); err != nil {
+		b.conn.Close()
+		return nil, i18n.Errorf("failed to start batch: %v", err)
+	}
+	batch = b
+	return b, nil
+}
+
+// Commit makes the changes of the batch permanent and closes it.
+func (b *Batch) Commit() error {
+	return b.end("COMMIT")
+}
+
+// Rollback discards the changes of the batch and closes it.
+func (b *Batch) Rollback() error {
+	return b.end("ROLLBACK")
+}
+
+func (b *Batch) end(stmt string) error {
+	batch = nil
+	err := b.exec(stmt)
+	if closeErr := b.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (b *Batch) exec(query string) error {
+	_, err := b.conn.ExecContext(context.Background(), query, nil)
+	return err
+}
+
+// Connect implements driver.Connector, for the handles InitDB returns.
+func (b *Batch) Connect(context.Context) (driver.Conn, error) {
+	return &batchConn{SQLiteConn: b.conn, batch: b}, nil
+}
+
+func (b *Batch) Driver() driver.Driver {
+	return sqliteDriver
+}
+
+// batchConn is the batch connection as seen by one handle. Closing it
+// leaves the connection open, and its transactions are savepoints.
+type batchConn struct {
+	*sqlite3.SQLiteConn
+	batch *Batch
+}
+
+func (c *batchConn) Close() error {
+	return nil
+}
+
+func (c *batchConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *batchConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.batch.savepoints++
+	sp := &savepoint{batch: c.batch, name: fmt.Sprintf("batch_%d", c.batch.savepoints)}
+	if err := sp.batch.exec("SAVEPOINT " + sp.name); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+type savepoint struct {
+	batch *Batch
+	name  string
+}
+
+func (sp *savepoint) Commit() error {
+	return sp.batch.exec("RELEASE " + sp.name)
+}
+
+func (sp *savepoint) Rollback() error {
+	if err := sp.batch.exec("ROLLBACK TO " + sp.name); err != nil {
+		return err
+	}
+	return sp.batch.exec("RELEASE " + sp.name)
+}
+
+// openBatch returns a handle on the open batch.
+func openBatch() *sql.DB {
+	return sql.OpenDB(batch)
+}
@@ -3,30 +3,285 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"path/filepath"
+	"strings"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/names"
 )
 
-func InitDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", "./books.db")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %v", err)
+// DefaultPath is the location of the SQLite database file unless
+// configured otherwise.
+const DefaultPath = "./books.db"
+
+// Path is the location of the SQLite database file.
+var Path = DefaultPath
+
+// CoversDir returns the directory holding cover images, next to the database.
+func CoversDir() string {
+	return filepath.Join(filepath.Dir(Path), "covers")
+}
+
+// A migration upgrades the schema by one version inside a transaction.
+type migration func(tx *sql.Tx) error
+
+func execSQL(query string) migration {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
 	}
+}
 
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS books (
+// migrations are applied in order; the index of the last applied one
+// (plus one) is stored in PRAGMA user_version.
+var migrations = []migration{
+	execSQL(`CREATE TABLE IF NOT EXISTS books (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		title TEXT NOT NULL,
 		author TEXT NOT NULL,
 		published_year INTEGER,
 		status TEXT DEFAULT 'unread'
-	);`
+	);`),
+	execSQL(`ALTER TABLE books ADD COLUMN publisher TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN language TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN original_language TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN original_title TEXT NOT NULL DEFAULT '';`),
+	execSQL(`ALTER TABLE books ADD COLUMN cover TEXT NOT NULL DEFAULT '';`),
+	createAuthors,
+	execSQL(`CREATE TABLE book_relations (
+		book_id INTEGER NOT NULL REFERENCES books(id),
+		type TEXT NOT NULL,
+		related_id INTEGER NOT NULL REFERENCES books(id),
+		PRIMARY KEY (book_id, type, related_id)
+	);
+	CREATE INDEX book_relations_related_id ON book_relations(related_id);`),
+	execSQL(`ALTER TABLE books ADD COLUMN location TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN format TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN purchase_date TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN purchase_price INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE books ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN estimated_value INTEGER NOT NULL DEFAULT 0;`),
+	// Indexes matching the sort expressions of package filter, so ordered
+	// and keyset-paginated listings do not need to sort the whole table.
+	execSQL(`CREATE INDEX books_title_sort ON books(title COLLATE NOCASE, id);
+	CREATE INDEX books_author_sort ON books(author COLLATE NOCASE, id);
+	CREATE INDEX books_year_sort ON books(COALESCE(published_year, 0), id);`),
+	execSQL(`ALTER TABLE books ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+	CREATE TABLE quotes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		book_id INTEGER NOT NULL REFERENCES books(id),
+		text TEXT NOT NULL,
+		page INTEGER
+	);
+	CREATE INDEX quotes_book_id ON quotes(book_id);`),
+	// The sort indexes again, now under the Unicode collation package
+	// filter sorts by, and the locale that collation was built for.
+	execSQL(`DROP INDEX books_title_sort;
+	DROP INDEX books_author_sort;
+	CREATE INDEX books_title_sort ON books(title COLLATE UNICODE, id);
+	CREATE INDEX books_author_sort ON books(author COLLATE UNICODE, id);
+	CREATE TABLE collation_locale (locale TEXT NOT NULL);`),
+	// Transliteration keys (see package translit) kept next to the text,
+	// so searches and filters do not transliterate every book each time.
+	execSQL(`ALTER TABLE books ADD COLUMN title_key TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN original_title_key TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN author_key TEXT NOT NULL DEFAULT '';
+	CREATE TRIGGER books_keys_insert AFTER INSERT ON books BEGIN
+		UPDATE books SET ` + bookKeys + ` WHERE id = NEW.id;
+	END;
+	CREATE TRIGGER books_keys_update AFTER UPDATE OF title, original_title, author, author_id ON books BEGIN
+		UPDATE books SET ` + bookKeys + ` WHERE id = NEW.id;
+	END;
+	CREATE TRIGGER author_aliases_keys_insert AFTER INSERT ON author_aliases BEGIN
+		UPDATE books SET ` + bookKeys + ` WHERE author_id = NEW.author_id;
+	END;
+	CREATE TRIGGER author_aliases_keys_update AFTER UPDATE ON author_aliases BEGIN
+		UPDATE books SET ` + bookKeys + ` WHERE author_id IN (OLD.author_id, NEW.author_id);
+	END;
+	UPDATE books SET ` + bookKeys + `;`),
+	// Books added without a status got an empty one instead of the default.
+	execSQL(`ALTER TABLE books ADD COLUMN isbn TEXT NOT NULL DEFAULT '';
+	UPDATE books SET status = 'unread' WHERE status IS NULL OR status = '';`),
+	execSQL(`ALTER TABLE books ADD COLUMN pages INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE books ADD COLUMN read_date TEXT NOT NULL DEFAULT '';`),
+	execSQL(`CREATE TABLE views (
+		name TEXT PRIMARY KEY,
+		filter TEXT NOT NULL DEFAULT '',
+		sort TEXT NOT NULL DEFAULT ''
+	);`),
+	normalizeStatuses,
+}
+
+// bookKeys assigns the transliteration keys of a book. The author key also
+// covers the aliases of the author record.
+const bookKeys = `title_key = translit_key(title),
+	original_title_key = translit_key(original_title),
+	author_key = translit_key(author || ' ' || COALESCE(
+		(SELECT group_concat(alias, ' ') FROM author_aliases WHERE author_id = books.author_id), ''))`
+
+// createAuthors introduces author records and links every existing book to
+// one. Names that only differ in spacing, punctuation or case share an author.
+func createAuthors(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE authors (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		sort_name TEXT NOT NULL DEFAULT '',
+		birth_year INTEGER,
+		death_year INTEGER,
+		country TEXT NOT NULL DEFAULT ''
+	);
+	CREATE TABLE author_aliases (
+		alias_key TEXT PRIMARY KEY,
+		alias TEXT NOT NULL,
+		author_id INTEGER NOT NULL REFERENCES authors(id)
+	);
+	CREATE INDEX author_aliases_author_id ON author_aliases(author_id);
+	ALTER TABLE books ADD COLUMN author_id INTEGER REFERENCES authors(id);
+	CREATE INDEX books_author_id ON books(author_id);`)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("SELECT DISTINCT author FROM books")
+	if err != nil {
+		return err
+	}
+	var authors []string
+	for rows.Next() {
+		var author string
+		if err := rows.Scan(&author); err != nil {
+			rows.Close()
+			return err
+		}
+		authors = append(authors, author)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, author := range authors {
+		key := names.Key(author)
+		if key == "" {
+			continue
+		}
+		var id int64
+		err := tx.QueryRow("SELECT author_id FROM author_aliases WHERE alias_key = ?", key).Scan(&id)
+		if err == sql.ErrNoRows {
+			name := names.Tidy(author)
+			result, err := tx.Exec("INSERT INTO authors (name, sort_name) VALUES (?, ?)", name, names.SortName(name))
+			if err != nil {
+				return err
+			}
+			id, _ = result.LastInsertId()
+			_, err = tx.Exec("INSERT INTO author_aliases (alias_key, alias, author_id) VALUES (?, ?, ?)", key, name, id)
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE books SET author_id = ? WHERE author = ?", id, author); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	_, err = db.Exec(createTableSQL)
+// normalizeStatuses corrects the spelling of statuses stored before they
+// were validated, such as "Read ", which would fail validation on every
+// later update of the book. Any other status is left for the user to
+// correct rather than guessed, and the migration fails listing the books.
+func normalizeStatuses(tx *sql.Tx) error {
+	_, err := tx.Exec(`UPDATE books SET status = CASE lower(trim(COALESCE(status, '')))
+		WHEN 'read' THEN 'read' ELSE 'unread' END
+	WHERE (status IS NULL OR status NOT IN ('read', 'unread'))
+		AND lower(trim(COALESCE(status, ''))) IN ('read', 'unread', '')`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create table: %v", err)
+		return err
+	}
+
+	rows, err := tx.Query("SELECT id, status FROM books WHERE status NOT IN ('read', 'unread') ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var invalid []string
+	for rows.Next() {
+		var id int
+		var status string
+		if err := rows.Scan(&id, &status); err != nil {
+			return err
+		}
+		invalid = append(invalid, fmt.Sprintf("%d (%q)", id, status))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(invalid) > 0 {
+		return i18n.Errorf("statuses other than read or unread in books %s: set them to read or unread in %s, e.g. with sqlite3, and try again",
+			strings.Join(invalid, ", "), Path)
+	}
+	return nil
+}
+
+// InitDB opens the database, migrating it to the current schema. Inside a
+// batch (see BeginBatch) it returns a handle on the batch instead.
+func InitDB() (*sql.DB, error) {
+	if batch != nil {
+		return openBatch(), nil
+	}
+
+	db, err := sql.Open(driverName, Path)
+	if err != nil {
+		return nil, i18n.Errorf("failed to open database: %v", err)
+	}
+
+	if err := detachSearchIndex(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := ensureCollation(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := ensureSearchIndex(db); err != nil {
+		db.Close()
+		return nil, err
 	}
 
 	//log.Println("Connected to SQLite database")
 	return db, nil
 }
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return i18n.Errorf("failed to read schema version: %v", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return i18n.Errorf("failed to start migration %d: %v", i+1, err)
+		}
+		if err := migrations[i](tx); err != nil {
+			tx.Rollback()
+			return i18n.Errorf("failed to apply migration %d: %v", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters.
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return i18n.Errorf("failed to record migration %d: %v", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return i18n.Errorf("failed to commit migration %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+// The full-text index needs SQLite built with FTS5, which go-sqlite3 only
+// does with the sqlite_fts5 build tag. It is therefore not a migration:
+// ensureSearchIndex creates it whenever the running binary supports FTS5 and
+// detaches it otherwise, so the same database works with both builds.
+
+// book_search_documents is the text indexed for every book, also searched
+// directly when FTS5 is missing. The author column also holds the aliases of
+// the author record, so pen names and other spellings find the book too.
+// The keys column holds the transliteration keys of title, original title
+// and author, see package translit. The view is recreated on every start,
+// which is cheap and keeps older databases up to date.
+const searchDocuments = `DROP VIEW IF EXISTS book_search_documents;
+	CREATE VIEW book_search_documents AS
+	SELECT b.id, b.title, b.original_title,
+		b.author || COALESCE('; ' || (
+			SELECT group_concat(alias, '; ') FROM author_aliases
+			WHERE author_id = b.author_id AND alias != b.author), '') AS author,
+		b.notes,
+		COALESCE((SELECT group_concat(text, ' … ') FROM quotes WHERE book_id = b.id), '') AS quotes,
+		trim(b.title_key || ' ' || b.original_title_key || ' ' || b.author_key) AS keys
+	FROM books b;`
+
+const searchIndex = `CREATE VIRTUAL TABLE IF NOT EXISTS books_fts USING fts5(
+	title, original_title, author, notes, quotes, keys,
+	tokenize = 'unicode61 remove_diacritics 2',
+	prefix = '2 3'
+);`
+
+// ftsColumns are the columns of books_fts and book_search_documents.
+const ftsColumns = "title, original_title, author, notes, quotes, keys"
+
+// reindex returns the statements refreshing the index entry of one book.
+func reindex(id string) string {
+	return fmt.Sprintf(`DELETE FROM books_fts WHERE rowid = %[1]s;
+		INSERT INTO books_fts(rowid, `+ftsColumns+`)
+		SELECT id, `+ftsColumns+` FROM book_search_documents WHERE id = %[1]s;`, id)
+}
+
+// reindexAuthor returns the statements refreshing the index entries of all
+// books of one author.
+func reindexAuthor(id string) string {
+	return fmt.Sprintf(`DELETE FROM books_fts WHERE rowid IN (SELECT id FROM books WHERE author_id = %[1]s);
+		INSERT INTO books_fts(rowid, `+ftsColumns+`)
+		SELECT id, `+ftsColumns+` FROM book_search_documents
+		WHERE id IN (SELECT id FROM books WHERE author_id = %[1]s);`, id)
+}
+
+// searchTriggers keep books_fts in sync with the tables it is built from.
+var searchTriggers = map[string]string{
+	"books_fts_insert": `AFTER INSERT ON books BEGIN ` + reindex("NEW.id") + ` END`,
+	"books_fts_update": `AFTER UPDATE ON books BEGIN ` + reindex("OLD.id") + reindex("NEW.id") + ` END`,
+	"books_fts_delete": `AFTER DELETE ON books BEGIN DELETE FROM books_fts WHERE rowid = OLD.id; END`,
+
+	"quotes_fts_insert": `AFTER INSERT ON quotes BEGIN ` + reindex("NEW.book_id") + ` END`,
+	"quotes_fts_update": `AFTER UPDATE ON quotes BEGIN ` + reindex("OLD.book_id") + reindex("NEW.book_id") + ` END`,
+	"quotes_fts_delete": `AFTER DELETE ON quotes BEGIN ` + reindex("OLD.book_id") + ` END`,
+
+	"author_aliases_fts_insert": `AFTER INSERT ON author_aliases BEGIN ` + reindexAuthor("NEW.author_id") + ` END`,
+	"author_aliases_fts_update": `AFTER UPDATE ON author_aliases BEGIN ` +
+		reindexAuthor("OLD.author_id") + reindexAuthor("NEW.author_id") + ` END`,
+}
+
+// HasFullTextSearch reports whether the SQLite library supports FTS5.
+func HasFullTextSearch(db *sql.DB) bool {
+	var used bool
+	err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used)
+	return err == nil && used
+}
+
+// detachSearchIndex drops the triggers of the full-text index when SQLite
+// lacks FTS5: the index table cannot be written to then, and every change
+// to books would fail, including those of migrations, so this runs first.
+func detachSearchIndex(db *sql.DB) error {
+	if HasFullTextSearch(db) {
+		return nil
+	}
+	for name := range searchTriggers {
+		if _, err := db.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+			return i18n.Errorf("failed to detach search index: %v", err)
+		}
+	}
+	return nil
+}
+
+// ensureSearchIndex creates the full-text index and its triggers, filling
+// the index when the triggers were missing, since the books may have changed
+// while they were. Without FTS5 only the search documents are created, see
+// detachSearchIndex.
+func ensureSearchIndex(db *sql.DB) error {
+	enabled := HasFullTextSearch(db)
+	tx, err := db.Begin()
+	if err != nil {
+		return i18n.Errorf("failed to update search index: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(searchDocuments); err != nil {
+		return i18n.Errorf("failed to create search documents: %v", err)
+	}
+	if !enabled {
+		return tx.Commit()
+	}
+
+	names := make([]string, 0, len(searchTriggers))
+	for name := range searchTriggers {
+		names = append(names, "'"+name+"'")
+	}
+	var found, keys int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (` +
+		strings.Join(names, ", ") + `)`).Scan(&found)
+	if err != nil {
+		return i18n.Errorf("failed to read search index: %v", err)
+	}
+	// Indexes built before the keys column was added are rebuilt too.
+	err = tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master m, pragma_table_info(m.name) c
+		WHERE m.name = 'books_fts' AND c.name = 'keys'`).Scan(&keys)
+	if err != nil {
+		return i18n.Errorf("failed to read search index: %v", err)
+	}
+	if found == len(searchTriggers) && keys == 1 {
+		return nil
+	}
+
+	statements := []string{"DROP TABLE IF EXISTS books_fts", searchIndex}
+	for name, body := range searchTriggers {
+		statements = append(statements,
+			"DROP TRIGGER IF EXISTS "+name,
+			"CREATE TRIGGER "+name+" "+body)
+	}
+	statements = append(statements,
+		`INSERT INTO books_fts(rowid, `+ftsColumns+`)
+		SELECT id, `+ftsColumns+` FROM book_search_documents`)
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return i18n.Errorf("failed to build search index: %v", err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/translit"
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// SQLite's LOWER, LIKE and NOCASE only fold ASCII letters, so "война" would
+// not find "Война" and Cyrillic titles would sort by code point. Every
+// connection therefore gets two additions:
+//
+//	unicode_fold(text)   full Unicode case folding, for LIKE: fold both sides
+//	COLLATE UNICODE      case-insensitive collation following the CLDR
+//	                     rules of the user's locale, for sorting and equality
+//
+// A third function, translit_key(text), computes the keys of package
+// translit for the triggers keeping them up to date.
+//
+// Indexes and triggers using these only work through this driver; the
+// sqlite3 shell can read the database but not change books.
+
+// driverName is the go-sqlite3 driver extended with the functions above.
+const driverName = "sqlite3_unicode"
+
+var sqliteDriver = &sqlite3.SQLiteDriver{
+	ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+		// Neither a Caser nor a Collator is safe for concurrent use,
+		// but a connection only runs one statement at a time.
+		fold := cases.Fold()
+		if err := conn.RegisterFunc("unicode_fold", func(s string) string {
+			return fold.String(s)
+		}, true); err != nil {
+			return err
+		}
+		if err := conn.RegisterFunc("translit_key", translit.Key, true); err != nil {
+			return err
+		}
+		coll := collate.New(collationLocale(), collate.IgnoreCase)
+		return conn.RegisterCollation("UNICODE", coll.CompareString)
+	},
+}
+
+func init() {
+	sql.Register(driverName, sqliteDriver)
+}
+
+// collationLocale returns the locale to sort by, taken from the environment
+// like the sort utility does. Without one the CLDR root order is used.
+func collationLocale() language.Tag {
+	for _, name := range []string{"LC_ALL", "LC_COLLATE", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		// "ru_RU.UTF-8@euro" -> "ru-RU"
+		value, _, _ = strings.Cut(value, ".")
+		value, _, _ = strings.Cut(value, "@")
+		if value == "C" || value == "POSIX" {
+			return language.Und
+		}
+		tag, err := language.Parse(strings.ReplaceAll(value, "_", "-"))
+		if err != nil {
+			return language.Und
+		}
+		return tag
+	}
+	return language.Und
+}
+
+// ensureCollation rebuilds the indexes using the UNICODE collation when the
+// locale changed since they were built: an index sorted under other rules
+// would make ordered listings and lookups skip rows.
+func ensureCollation(db *sql.DB) error {
+	locale := collationLocale().String()
+	var indexed string
+	err := db.QueryRow("SELECT locale FROM collation_locale").Scan(&indexed)
+	if err != nil && err != sql.ErrNoRows {
+		return i18n.Errorf("failed to read collation locale: %v", err)
+	}
+	if err == nil && indexed == locale {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return i18n.Errorf("failed to update collation: %v", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		"REINDEX UNICODE",
+		"DELETE FROM collation_locale",
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return i18n.Errorf("failed to update collation: %v", err)
+		}
+	}
+	if _, err := tx.Exec("INSERT INTO collation_locale (locale) VALUES (?)", locale); err != nil {
+		return i18n.Errorf("failed to update collation: %v", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+)
+
+// coverWidth is the thumbnail width in terminal columns.
+const coverWidth = 24
+
+// renderCover draws the image at path as a true-color thumbnail. Every
+// character cell shows two vertically stacked pixels: the upper half block
+// takes the foreground color and the cell background fills the lower half.
+func renderCover(path string, width int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return "", i18n.Errorf("empty image")
+	}
+	if width > bounds.Dx() {
+		width = bounds.Dx()
+	}
+	// Pixels are square, two of them per cell vertically.
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 2 {
+		height = 2
+	}
+	height -= height % 2
+
+	var sb strings.Builder
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			tr, tg, tb := averageColor(img, x, y, width, height)
+			br, bg, bb := averageColor(img, x, y+1, width, height)
+			fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr, tg, tb, br, bg, bb)
+		}
+		sb.WriteString("\x1b[0m\n")
+	}
+	return sb.String(), nil
+}
+
+// averageColor returns the mean 8-bit color of the source area that maps to
+// thumbnail pixel (x, y) of a width×height thumbnail.
+func averageColor(img image.Image, x, y, width, height int) (uint8, uint8, uint8) {
+	b := img.Bounds()
+	x0 := b.Min.X + x*b.Dx()/width
+	x1 := b.Min.X + (x+1)*b.Dx()/width
+	y0 := b.Min.Y + y*b.Dy()/height
+	y1 := b.Min.Y + (y+1)*b.Dy()/height
+	if x1 == x0 {
+		x1++
+	}
+	if y1 == y0 {
+		y1++
+	}
+
+	var r, g, bl, n uint64
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			cr, cg, cb, _ := img.At(px, py).RGBA()
+			r += uint64(cr)
+			g += uint64(cg)
+			bl += uint64(cb)
+			n++
+		}
+	}
+	return uint8((r / n) >> 8), uint8((g / n) >> 8), uint8((bl / n) >> 8)
+}
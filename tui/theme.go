@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme задаёт цвета интерфейса. Пустой цвет означает цвет терминала.
+type Theme struct {
+	Accent   lipgloss.Color // заголовки и фон выбранной строки
+	Selected lipgloss.Color // текст выбранной строки
+	Read     lipgloss.Color
+	Unread   lipgloss.Color
+	Help     lipgloss.Color
+	Active   lipgloss.Color // активное поле формы
+	Error    lipgloss.Color
+	Hit      lipgloss.Color // совпадения в результатах поиска
+}
+
+// Themes — встроенные темы по именам.
+var Themes = map[string]Theme{
+	"dark":  {Accent: "62", Selected: "230", Read: "10", Unread: "9", Help: "240", Active: "39", Error: "1", Hit: "214"},
+	"light": {Accent: "25", Selected: "231", Read: "28", Unread: "124", Help: "244", Active: "26", Error: "160", Hit: "166"},
+	"mono":  {},
+}
+
+// ThemeNames возвращает имена встроенных тем по алфавиту.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme возвращает встроенную тему по имени.
+func LookupTheme(name string) (Theme, error) {
+	theme, ok := Themes[strings.ToLower(name)]
+	if !ok {
+		return Theme{}, i18n.Errorf("unknown theme %q (valid: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return theme, nil
+}
+
+// styles — стили интерфейса в цветах темы.
+type styles struct {
+	title, selected, normal, read, unread, help, activeField, error, hit lipgloss.Style
+
+	// вкладки представлений
+	tab, activeTab lipgloss.Style
+}
+
+func (t Theme) styles() styles {
+	s := styles{
+		title:       lipgloss.NewStyle().Bold(true).Foreground(t.Accent),
+		selected:    lipgloss.NewStyle().Background(t.Accent).Foreground(t.Selected),
+		normal:      lipgloss.NewStyle().PaddingLeft(2),
+		read:        lipgloss.NewStyle().Foreground(t.Read),
+		unread:      lipgloss.NewStyle().Foreground(t.Unread),
+		help:        lipgloss.NewStyle().Foreground(t.Help),
+		activeField: lipgloss.NewStyle().Foreground(t.Active).Bold(true),
+		error:       lipgloss.NewStyle().Foreground(t.Error).Bold(true),
+		hit:         lipgloss.NewStyle().Bold(true).Foreground(t.Hit),
+		tab:         lipgloss.NewStyle().Padding(0, 1).Foreground(t.Help),
+		activeTab:   lipgloss.NewStyle().Padding(0, 1).Bold(true).Background(t.Accent).Foreground(t.Selected),
+	}
+	// Без цветов выбранную строку выделяем инверсией
+	if t.Accent == "" {
+		s.selected = lipgloss.NewStyle().Reverse(true)
+		s.activeTab = s.activeTab.Reverse(true)
+	}
+	return s
+}
@@ -4,9 +4,16 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/i18n"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/models"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/repository"
+	"github.com/belokosoff/golang-cobra-cli-crud/internal/validation"
+	"github.com/belokosoff/golang-cobra-cli-crud/pkg/db"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	_ "github.com/mattn/go-sqlite3"
@@ -14,73 +21,155 @@ import (
 
 type model struct {
 	db          *sql.DB
-	books       []Book
+	repo        *repository.BookRepository
+	books       []models.Book
 	cursor      int
 	view        string
 	title       string
 	author      string
 	year        string
 	status      string
-	activeField int // 0: title, 1: author, 2: year, 3: status
+	activeField int    // 0: title, 1: author, 2: year, 3: status
+	cover       string // отрисованная обложка книги в режиме карточки
+	authorName  string // каноническое имя автора книги в режиме карточки
+	relations   []models.Relation
+	searching   bool           // вводится поисковый запрос
+	query       string         // поисковый запрос; пустой — показываются все книги
+	snippets    map[int]string // фрагменты с совпадениями по ID книги
+	fuzzy       bool           // точных совпадений нет, показаны похожие книги
+	addErr      string         // почему не удалось добавить книгу
+	views       []models.View  // сохранённые представления, вкладки после «All books»
+	tab         int            // 0 — все книги, иначе представление views[tab-1]
+	err         error          // список не удалось прочитать; Start вернёт эту ошибку
+	styles      styles
 }
 
-type Book struct {
-	ID            int
-	Title         string
-	Author        string
-	PublishedYear int
-	Status        string
+// searchLimit ограничивает число результатов поиска в списке.
+const searchLimit = 50
+
+// refreshInterval — как часто список перечитывается, чтобы вкладки
+// отражали и изменения, сделанные командами в другом терминале.
+const refreshInterval = 5 * time.Second
+
+type refreshMsg struct{}
+
+func refresh() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg { return refreshMsg{} })
 }
 
-func initialModel(db *sql.DB) model {
-	books := fetchBooks(db)
-	return model{
+func initialModel(db *sql.DB, theme Theme) model {
+	m := model{
 		db:     db,
-		books:  books,
+		repo:   repository.NewBookRepository(db),
 		view:   "list",
 		status: "unread",
+		styles: theme.styles(),
+	}
+	m.reload()
+	return m
+}
+
+func fetchBooks(repo *repository.BookRepository) ([]models.Book, error) {
+	books, err := repo.GetAllBooks()
+	if err != nil {
+		return nil, i18n.Errorf("failed to find books: %w", err)
 	}
+	return books, nil
 }
 
-func fetchBooks(db *sql.DB) []Book {
-	rows, err := db.Query("SELECT id, title, author, published_year, status FROM books")
+// reload перечитывает список: книги текущей вкладки или результаты
+// текущего поиска.
+func (m *model) reload() {
+	m.snippets = nil
+	views, err := m.repo.GetViews()
 	if err != nil {
-		log.Fatal(err)
+		log.Println(i18n.T("Error loading views:"), err)
+	}
+	m.views = views
+	if m.tab > len(m.views) {
+		m.tab = 0
 	}
-	defer rows.Close()
 
-	var books []Book
-	for rows.Next() {
-		var b Book
-		err := rows.Scan(&b.ID, &b.Title, &b.Author, &b.PublishedYear, &b.Status)
+	if m.query == "" && m.tab > 0 {
+		view := m.views[m.tab-1]
+		page, err := m.repo.ListBooks(repository.ListOptions{Where: view.Where, Sort: view.Sort})
+		if err != nil {
+			log.Println(i18n.T("Error running view:"), err)
+		}
+		m.books = page.Books
+	} else if m.query == "" {
+		m.books, m.err = fetchBooks(m.repo)
+	} else {
+		results, err := m.repo.Search(m.query, searchLimit)
 		if err != nil {
-			log.Fatal(err)
+			log.Println(i18n.T("Error searching books:"), err)
+		}
+		// Если точно ничего не нашлось, ищем с учётом опечаток
+		m.fuzzy = len(results) == 0
+		if m.fuzzy {
+			results, err = m.repo.FuzzySearch(m.query, searchLimit)
+			if err != nil {
+				log.Println(i18n.T("Error searching books:"), err)
+			}
+		}
+		m.books = make([]models.Book, len(results))
+		m.snippets = make(map[int]string, len(results))
+		for i, res := range results {
+			m.books[i] = res.Book
+			m.snippets[res.ID] = res.Highlight(func(hit string) string {
+				return m.styles.hit.Render(hit)
+			})
 		}
-		books = append(books, b)
 	}
-	return books
+	if m.cursor >= len(m.books) {
+		m.cursor = max(len(m.books)-1, 0)
+	}
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return refresh()
 }
 
+// Update обрабатывает сообщение и завершает программу, если список книг
+// не удалось прочитать.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if m := next.(model); m.err != nil {
+		return m, tea.Quit
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.view == "add" && msg != nil {
 		if errMsg, ok := msg.(error); ok {
-			log.Println("Error:", errMsg)
+			log.Println(i18n.T("Error:"), errMsg)
 			return m, nil
 		}
 	}
 
 	switch msg := msg.(type) {
+	case refreshMsg:
+		if m.view == "list" && !m.searching {
+			m.reload()
+		}
+		return m, refresh()
+
 	case tea.KeyMsg:
 		// Обработка команд, которые работают в любом режиме
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			if m.view == "add" {
 				m.view = "list"
-				m.books = fetchBooks(m.db)
+				m.reload()
+			} else if m.view == "detail" {
+				m.view = "list"
+			} else if m.view == "list" && msg.String() == "esc" && (m.searching || m.query != "") {
+				// Esc сначала сбрасывает поиск, а уже потом закрывает программу
+				m.searching = false
+				m.query = ""
+				m.cursor = 0
+				m.reload()
 			} else {
 				return m, tea.Quit
 			}
@@ -90,6 +179,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Обработка команд для конкретных режимов
 		switch m.view {
 		case "list":
+			if m.searching {
+				switch msg.Type {
+				case tea.KeyEnter:
+					m.searching = false
+					m.cursor = 0
+					m.reload()
+				case tea.KeyBackspace:
+					if rs := []rune(m.query); len(rs) > 0 {
+						m.query = string(rs[:len(rs)-1])
+					}
+				case tea.KeySpace:
+					m.query += " "
+				case tea.KeyRunes:
+					m.query += string(msg.Runes)
+				}
+				return m, nil
+			}
+
 			switch msg.String() {
 			case "up", "k":
 				if m.cursor > 0 {
@@ -106,35 +213,66 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.author = ""
 				m.year = ""
 				m.status = "unread"
+				m.addErr = ""
+			case "tab", "shift+tab":
+				// Вкладка перечитывается при каждом переключении
+				step := 1
+				if msg.String() == "shift+tab" {
+					step = len(m.views)
+				}
+				m.tab = (m.tab + step) % (len(m.views) + 1)
+				m.query = ""
+				m.cursor = 0
+				m.reload()
 			case "s":
 				m.view = "stats"
+			case "/":
+				m.searching = true
 			case "enter":
-				// В режиме списка enter не делает ничего
-			case "d":
 				if len(m.books) > 0 {
-					_, err := m.db.Exec("DELETE FROM books WHERE id = ?", m.books[m.cursor].ID)
+					m.view = "detail"
+					m.authorName = ""
+					if id := m.books[m.cursor].AuthorID; id != 0 {
+						author, err := repository.NewAuthorRepository(m.db).GetAuthorByID(id)
+						if err != nil {
+							log.Println(i18n.T("Error loading author:"), err)
+						}
+						m.authorName = author.Name
+					}
+					relations, err := m.repo.GetRelations(m.books[m.cursor].ID)
 					if err != nil {
-						log.Println("Error deleting book:", err)
+						log.Println(i18n.T("Error loading related books:"), err)
 					}
-					m.books = fetchBooks(m.db)
-					if m.cursor >= len(m.books) {
-						m.cursor = len(m.books) - 1
+					m.relations = relations
+					m.cover = ""
+					if name := m.books[m.cursor].Cover; name != "" {
+						cover, err := renderCover(filepath.Join(db.CoversDir(), name), coverWidth)
+						if err != nil {
+							log.Println(i18n.T("Error rendering cover:"), err)
+						}
+						m.cover = cover
 					}
 				}
+			case "d":
+				if len(m.books) > 0 {
+					err := m.repo.DeleteBook(m.books[m.cursor].ID)
+					if err != nil {
+						log.Println(i18n.T("Error deleting book:"), err)
+					}
+					m.reload()
+				}
 			case "t":
 				if len(m.books) > 0 {
-					newStatus := "read"
+					book := m.books[m.cursor]
+					book.Status = "read"
 					if m.books[m.cursor].Status == "read" {
-						newStatus = "unread"
+						book.Status = "unread"
 					}
-					_, err := m.db.Exec(
-						"UPDATE books SET status = ? WHERE id = ?",
-						newStatus, m.books[m.cursor].ID,
-					)
+					err := m.repo.UpdateBook(book)
 					if err != nil {
-						log.Println("Error updating status:", err)
+						log.Println(i18n.T("Error updating status:"), err)
 					}
-					m.books = fetchBooks(m.db)
+					m.reload()
 				}
 			}
 
@@ -145,37 +283,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "shift+tab":
 				m.activeField = (m.activeField - 1 + 4) % 4
 			case "enter":
-				if strings.TrimSpace(m.title) == "" {
-					log.Println("Title cannot be empty")
+				// Год обязателен, как и для команды add; остальное
+				// проверяет репозиторий
+				y := strings.TrimSpace(m.year)
+				year, err := strconv.Atoi(y)
+				if y != "" && err != nil {
+					m.addErr = "year: " + i18n.Sprintf("%q is not a year", y)
 					return m, nil
 				}
-				if strings.TrimSpace(m.author) == "" {
-					log.Println("Author cannot be empty")
-					return m, nil
-				}
-				if strings.TrimSpace(m.year) == "" {
-					log.Println("Year cannot be empty")
+				if err := validation.Year(year); err != nil {
+					m.addErr = err.Error()
 					return m, nil
 				}
 
-				year, err := strconv.Atoi(m.year)
+				_, err = m.repo.AddBook(models.Book{
+					Title:         strings.TrimSpace(m.title),
+					Author:        strings.TrimSpace(m.author),
+					PublishedYear: year,
+					Status:        m.status,
+				})
 				if err != nil {
-					log.Println("Invalid year format")
+					m.addErr = err.Error()
 					return m, nil
 				}
-
-				_, err = m.db.Exec(
-					"INSERT INTO books (title, author, published_year, status) VALUES (?, ?, ?, ?)",
-					strings.TrimSpace(m.title),
-					strings.TrimSpace(m.author),
-					year,
-					m.status,
-				)
-				if err != nil {
-					log.Println("Error adding book:", err)
-				}
+				m.addErr = ""
 				m.view = "list"
-				m.books = fetchBooks(m.db)
+				m.reload()
 				m.title = ""
 				m.author = ""
 				m.year = ""
@@ -228,8 +361,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case "stats":
-			// В режиме статистики не обрабатываем специальные команды
+		case "stats", "detail":
+			// В режимах статистики и карточки книги не обрабатываем специальные команды
 		}
 	}
 
@@ -239,79 +372,149 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m model) View() string {
 	var sb strings.Builder
 
-	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
-	selectedStyle := lipgloss.NewStyle().Background(lipgloss.Color("62")).Foreground(lipgloss.Color("230"))
-	normalStyle := lipgloss.NewStyle().PaddingLeft(2)
-	readStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-	unreadStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	activeFieldStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
-	//errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+	titleStyle := m.styles.title
+	selectedStyle := m.styles.selected
+	normalStyle := m.styles.normal
+	readStyle := m.styles.read
+	unreadStyle := m.styles.unread
+	helpStyle := m.styles.help
+	activeFieldStyle := m.styles.activeField
+	errorStyle := m.styles.error
 
 	switch m.view {
 	case "list":
-		sb.WriteString(titleStyle.Render("Your Book Collection\n"))
+		if len(m.views) == 0 {
+			sb.WriteString(titleStyle.Render(i18n.T("Your Book Collection") + "\n"))
+		} else {
+			sb.WriteString(titleStyle.Render(i18n.T("Your Book Collection")) + "\n")
+			tabs := []string{i18n.T("All books")}
+			for _, view := range m.views {
+				tabs = append(tabs, view.Name)
+			}
+			for i, tab := range tabs {
+				if i == m.tab && m.query == "" {
+					sb.WriteString(m.styles.activeTab.Render(tab))
+				} else {
+					sb.WriteString(m.styles.tab.Render(tab))
+				}
+			}
+			sb.WriteString("\n")
+		}
+		if m.searching {
+			sb.WriteString(i18n.Sprintf("Search: %s", m.query) + "▏\n")
+		} else if m.query != "" {
+			found := i18n.N(len(m.books), "%d found", "%d found", len(m.books))
+			if m.fuzzy {
+				found = i18n.N(len(m.books), "no exact matches, %d similar", "no exact matches, %d similar", len(m.books))
+			}
+			sb.WriteString(i18n.Sprintf("Search: %s (%s)", m.query, found) + "\n")
+		}
 		for i, book := range m.books {
 			status := readStyle.Render("✓ ")
 			if book.Status == "unread" {
 				status = unreadStyle.Render("✗ ")
 			}
 
+			line := status + " " + i18n.Sprintf("%s by %s (%d)", book.Title, book.Author, book.PublishedYear)
 			if m.cursor == i {
-				sb.WriteString(selectedStyle.Render(fmt.Sprintf(
-					"%s %s by %s (%d)",
-					status, book.Title, book.Author, book.PublishedYear,
-				)))
+				sb.WriteString(selectedStyle.Render(line))
 			} else {
-				sb.WriteString(normalStyle.Render(fmt.Sprintf(
-					"%s %s by %s (%d)",
-					status, book.Title, book.Author, book.PublishedYear,
-				)))
+				sb.WriteString(normalStyle.Render(line))
 			}
 			sb.WriteString("\n")
+			if snippet := m.snippets[book.ID]; snippet != "" {
+				sb.WriteString("      " + snippet + "\n")
+			}
 		}
 
-		sb.WriteString("\n" + helpStyle.Render(
-			"↑/↓: Navigate • a: Add • d: Delete • t: Toggle status • s: Stats • q: Quit",
-		))
-
-	case "add":
-		sb.WriteString(titleStyle.Render("Add New Book\n\n"))
-
-		// Title field
-		titleLabel := "Title:"
-		if m.activeField == 0 {
-			titleLabel = activeFieldStyle.Render(titleLabel)
+		help := "↑/↓: Navigate • Enter: Details • /: Search • a: Add • d: Delete • t: Toggle status • s: Stats • q: Quit"
+		if len(m.views) > 0 {
+			help = "↑/↓: Navigate • Tab: Next view • Enter: Details • /: Search • a: Add • d: Delete • t: Toggle status • s: Stats • q: Quit"
 		}
-		sb.WriteString(fmt.Sprintf("%s %s\n", titleLabel, m.title))
-
-		// Author field
-		authorLabel := "Author:"
-		if m.activeField == 1 {
-			authorLabel = activeFieldStyle.Render(authorLabel)
+		if m.searching {
+			help = "Enter: Search • Esc: Cancel"
+		} else if m.query != "" {
+			help = "↑/↓: Navigate • Enter: Details • /: New search • Esc: Show all books"
 		}
-		sb.WriteString(fmt.Sprintf("%s %s\n", authorLabel, m.author))
+		sb.WriteString("\n" + helpStyle.Render(i18n.T(help)))
 
-		// Year field
-		yearLabel := "Year:"
-		if m.activeField == 2 {
-			yearLabel = activeFieldStyle.Render(yearLabel)
+	case "detail":
+		book := m.books[m.cursor]
+		sb.WriteString(titleStyle.Render(book.Title + "\n\n"))
+		if m.cover != "" {
+			sb.WriteString(m.cover + "\n")
+		}
+		author := book.Author
+		if m.authorName != "" && m.authorName != book.Author {
+			author = fmt.Sprintf("%s (%s)", book.Author, m.authorName)
+		}
+		fields := [][2]string{
+			{"Author:", author},
+			{"Year:", strconv.Itoa(book.PublishedYear)},
+			{"Status:", i18n.T(book.Status)},
+			{"Publisher:", book.Publisher},
+			{"Language:", book.Language},
+		}
+		if book.IsTranslation() {
+			fields = append(fields,
+				[2]string{"Original language:", book.OriginalLanguage},
+				[2]string{"Original title:", book.OriginalTitle})
+		}
+		if book.ISBN != "" {
+			fields = append(fields, [2]string{"ISBN:", book.ISBN})
 		}
-		sb.WriteString(fmt.Sprintf("%s %s\n", yearLabel, m.year))
+		if book.Pages != 0 {
+			fields = append(fields, [2]string{"Pages:", i18n.Number(float64(book.Pages), 0)})
+		}
+		if book.Location != "" {
+			fields = append(fields, [2]string{"Location:", book.Location})
+		}
+		if book.Format != "" {
+			fields = append(fields, [2]string{"Format:", book.Format})
+		}
+		if book.Currency != "" {
+			value := i18n.Number(float64(book.Value())/100, 2) + " " + book.Currency
+			fields = append(fields, [2]string{"Value:", value})
+		}
+		writeFields(&sb, fields)
+		if len(m.relations) > 0 {
+			sb.WriteString("\n" + i18n.T("Related books:") + "\n")
+			for _, rel := range m.relations {
+				sb.WriteString(fmt.Sprintf("  %s: %s\n", i18n.T(rel.Label()), rel.RelatedTitle))
+			}
+		}
+		sb.WriteString("\n" + helpStyle.Render(i18n.T("Esc: Back to list")))
+
+	case "add":
+		sb.WriteString(titleStyle.Render(i18n.T("Add New Book") + "\n\n"))
 
-		// Status field
-		statusLabel := "Status:"
-		if m.activeField == 3 {
-			statusLabel = activeFieldStyle.Render(statusLabel)
+		// Метка активного поля выделяется, значения выравниваются по
+		// самой длинной метке
+		labels := []string{i18n.T("Title:"), i18n.T("Author:"), i18n.T("Year:"), i18n.T("Status:")}
+		values := []string{m.title, m.author, m.year, i18n.T(m.status)}
+		width := 0
+		for _, label := range labels {
+			width = max(width, lipgloss.Width(label))
 		}
-		statusValue := m.status
-		if m.activeField == 3 {
-			statusValue = activeFieldStyle.Render(statusValue)
+		for i, label := range labels {
+			label += strings.Repeat(" ", width-lipgloss.Width(label))
+			value := values[i]
+			if m.activeField == i {
+				label = activeFieldStyle.Render(label)
+				if i == 3 {
+					value = activeFieldStyle.Render(value)
+				}
+			}
+			sb.WriteString(label + " " + value + "\n")
+		}
+		sb.WriteString("\n")
+
+		if m.addErr != "" {
+			sb.WriteString(errorStyle.Render(m.addErr) + "\n\n")
 		}
-		sb.WriteString(fmt.Sprintf("%s %s\n\n", statusLabel, statusValue))
 
 		sb.WriteString(helpStyle.Render(
-			"Tab/Shift+Tab: Move between fields • Space: Toggle status • Enter: Save • Esc: Cancel",
+			i18n.T("Tab/Shift+Tab: Move between fields • Space: Toggle status • Enter: Save • Esc: Cancel"),
 		))
 
 	case "stats":
@@ -319,19 +522,44 @@ func (m model) View() string {
 		m.db.QueryRow("SELECT COUNT(*) FROM books").Scan(&total)
 		m.db.QueryRow("SELECT COUNT(*) FROM books WHERE status = 'read'").Scan(&read)
 
-		sb.WriteString(titleStyle.Render("Statistics\n\n"))
-		sb.WriteString(fmt.Sprintf("Total books: %d\n", total))
-		sb.WriteString(fmt.Sprintf("Read:       %d (%.0f%%)\n", read, float64(read)/float64(total)*100))
-		sb.WriteString(fmt.Sprintf("Unread:     %d (%.0f%%)\n\n", total-read, float64(total-read)/float64(total)*100))
-		sb.WriteString(helpStyle.Render("Esc: Back to list"))
+		share := func(n int) string {
+			return fmt.Sprintf("%s (%s%%)", i18n.Number(float64(n), 0), i18n.Number(float64(n)/float64(total)*100, 0))
+		}
+		sb.WriteString(titleStyle.Render(i18n.T("Statistics") + "\n\n"))
+		writeFields(&sb, [][2]string{
+			{"Total books:", i18n.Number(float64(total), 0)},
+			{"Read:", share(read)},
+			{"Unread:", share(total - read)},
+		})
+		sb.WriteString("\n" + helpStyle.Render(i18n.T("Esc: Back to list")))
 	}
 
 	return sb.String()
 }
 
-func Start(db *sql.DB) {
-	p := tea.NewProgram(initialModel(db))
-	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+// writeFields пишет строки «метка значение», переводя метки и выравнивая
+// значения по самой длинной из них.
+func writeFields(sb *strings.Builder, fields [][2]string) {
+	width := 0
+	for _, f := range fields {
+		width = max(width, lipgloss.Width(i18n.T(f[0])))
+	}
+	for _, f := range fields {
+		label := i18n.T(f[0])
+		sb.WriteString(label + strings.Repeat(" ", width-lipgloss.Width(label)) + " " + f[1] + "\n")
+	}
+}
+
+// Start запускает интерфейс и возвращает ошибку, из-за которой он
+// завершился, если такая была.
+func Start(db *sql.DB, theme Theme) error {
+	m := initialModel(db, theme)
+	if m.err != nil {
+		return m.err
+	}
+	final, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return err
 	}
+	return final.(model).err
 }